@@ -0,0 +1,298 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+)
+
+// AuditEntry records a change applied to the groups of a tenant.
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	// RunID identifies the run that applied the change.
+	RunID string `json:"runID"`
+	// ConfigCommit is the git commit of the groups config the change was
+	// applied from, if it is in a git checkout.
+	ConfigCommit string `json:"configCommit,omitempty"`
+	Tenant       string `json:"tenant"`
+	// Action is the kind of change, one of the kinds counted in the metrics.
+	Action string `json:"action"`
+	Group  string `json:"group"`
+	Member string `json:"member,omitempty"`
+	// Before and After are the group, member or settings before and after
+	// the change, as returned by the API. Before is empty for creations and
+	// After for deletions.
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditLog is an append-only file of AuditEntry, one JSON object per line.
+type AuditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewAuditLog returns an AuditLog appending to the file at path.
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Append writes e at the end of the audit log, creating it if necessary.
+func (a *AuditLog) Append(e AuditEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("unable to encode audit entry: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, err := os.OpenFile(a.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write to audit log %s: %w", a.path, err)
+	}
+	return f.Close()
+}
+
+// ReadAuditLog returns the entries of the audit log at path for which keep
+// returns true, in the order they were appended.
+func ReadAuditLog(path string, keep func(e AuditEntry) bool) ([]AuditEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("error parsing audit log %s at line %d: %w", path, line, err)
+		}
+		if keep(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading audit log %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// newRunID returns a random ID for the audit entries of a run.
+func newRunID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// gitCommit returns the commit checked out in the git repository containing
+// dir, or an empty string if there is none.
+func gitCommit(dir string) string {
+	out, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// tenantAudit appends the changes applied to the groups of a tenant to an
+// AuditLog.
+type tenantAudit struct {
+	log *AuditLog
+	// base holds the fields shared by the entries of the tenant in a run.
+	base AuditEntry
+}
+
+// record appends a change of the given kind to the audit log.
+func (ta *tenantAudit) record(action, group, member string, before, after interface{}) error {
+	e := ta.base
+	e.Time = time.Now().UTC()
+	e.Action = action
+	e.Group = group
+	e.Member = member
+	var err error
+	if e.Before, err = auditState(before); err != nil {
+		return err
+	}
+	if e.After, err = auditState(after); err != nil {
+		return err
+	}
+	if err := ta.log.Append(e); err != nil {
+		return fmt.Errorf("%s of %s was applied but not audited: %w", action, group, err)
+	}
+	return nil
+}
+
+// auditState encodes the state of a group, member or settings for an
+// AuditEntry. Nil values are left out.
+func auditState(v interface{}) (json.RawMessage, error) {
+	if v == nil || v == (*admin.Group)(nil) || v == (*admin.Member)(nil) || v == (*groupssettings.Groups)(nil) {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode audit state: %w", err)
+	}
+	return b, nil
+}
+
+// auditedAdminServiceClient is an AdminServiceClient appending every
+// successful change made through client to an audit log. The state of the
+// group or member before an update or a deletion is fetched beforehand.
+type auditedAdminServiceClient struct {
+	AdminServiceClient
+	audit *tenantAudit
+}
+
+func (c *auditedAdminServiceClient) InsertGroup(group *admin.Group) (*admin.Group, error) {
+	g, err := c.AdminServiceClient.InsertGroup(group)
+	if err != nil {
+		return g, err
+	}
+	return g, c.audit.record(CreateGroupChange, group.Email, "", nil, g)
+}
+
+func (c *auditedAdminServiceClient) UpdateGroup(groupKey string, group *admin.Group) (*admin.Group, error) {
+	before, _ := c.AdminServiceClient.GetGroup(groupKey)
+	g, err := c.AdminServiceClient.UpdateGroup(groupKey, group)
+	if err != nil {
+		return g, err
+	}
+	return g, c.audit.record(UpdateGroupChange, groupKey, "", before, g)
+}
+
+func (c *auditedAdminServiceClient) DeleteGroup(groupKey string) error {
+	before, _ := c.AdminServiceClient.GetGroup(groupKey)
+	if err := c.AdminServiceClient.DeleteGroup(groupKey); err != nil {
+		return err
+	}
+	return c.audit.record(DeleteGroupChange, groupKey, "", before, nil)
+}
+
+func (c *auditedAdminServiceClient) InsertMember(groupKey string, member *admin.Member) (*admin.Member, error) {
+	m, err := c.AdminServiceClient.InsertMember(groupKey, member)
+	if err != nil {
+		return m, err
+	}
+	return m, c.audit.record(AddMemberChange, groupKey, member.Email, nil, m)
+}
+
+func (c *auditedAdminServiceClient) UpdateMember(groupKey, memberKey string, member *admin.Member) (*admin.Member, error) {
+	before, _ := c.AdminServiceClient.GetMember(groupKey, memberKey)
+	m, err := c.AdminServiceClient.UpdateMember(groupKey, memberKey, member)
+	if err != nil {
+		return m, err
+	}
+	return m, c.audit.record(UpdateMemberChange, groupKey, memberEmail(before, memberKey), before, m)
+}
+
+func (c *auditedAdminServiceClient) DeleteMember(groupKey, memberKey string) error {
+	before, _ := c.AdminServiceClient.GetMember(groupKey, memberKey)
+	if err := c.AdminServiceClient.DeleteMember(groupKey, memberKey); err != nil {
+		return err
+	}
+	return c.audit.record(RemoveMemberChange, groupKey, memberEmail(before, memberKey), before, nil)
+}
+
+// memberEmail returns the email of m, or memberKey if m is unknown.
+func memberEmail(m *admin.Member, memberKey string) string {
+	if m != nil && m.Email != "" {
+		return m.Email
+	}
+	return memberKey
+}
+
+var _ AdminServiceClient = (*auditedAdminServiceClient)(nil)
+
+// auditedGroupServiceClient is a GroupServiceClient appending every
+// successful change made through client to an audit log.
+type auditedGroupServiceClient struct {
+	GroupServiceClient
+	audit *tenantAudit
+}
+
+func (c *auditedGroupServiceClient) Patch(groupUniqueID string, groups *groupssettings.Groups) (*groupssettings.Groups, error) {
+	before, _ := c.GroupServiceClient.Get(groupUniqueID)
+	g, err := c.GroupServiceClient.Patch(groupUniqueID, groups)
+	if err != nil {
+		return g, err
+	}
+	return g, c.audit.record(UpdateSettingsChange, groupUniqueID, "", before, g)
+}
+
+var _ GroupServiceClient = (*auditedGroupServiceClient)(nil)
+
+// auditCommand registers the flags of the audit command, which prints the
+// entries of the audit log about a group or a member.
+func auditCommand() func(configFilePath string) error {
+	group := flag.String("group", "", "if set, only print the changes to this group")
+	member := flag.String("member", "", "if set, only print the changes to this member")
+	file := flag.String("audit-log", "", "the audit log to read, instead of the one in the config")
+
+	return func(configFilePath string) error {
+		path := *file
+		if path == "" {
+			var c Config
+			if err := c.Load(configFilePath, false); err != nil {
+				return err
+			}
+			if c.AuditLogPath == "" {
+				return fmt.Errorf("no audit-log in the config, use --audit-log to pick the audit log to read")
+			}
+			path = c.AuditLogPath
+		}
+
+		entries, err := ReadAuditLog(path, func(e AuditEntry) bool {
+			return (*group == "" || strings.EqualFold(e.Group, *group)) &&
+				(*member == "" || strings.EqualFold(e.Member, *member))
+		})
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
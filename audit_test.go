@@ -0,0 +1,92 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+func TestAuditLog(t *testing.T) {
+	ac := newFakeAdminServiceClient()
+	gc := newFakeGroupServiceClient()
+	settings := defaultSettings()
+	settings.WhoCanJoin = "ANYONE_CAN_JOIN"
+	addFakeGroup(ac, gc, "team@example.com", settings,
+		"owner@example.com", OwnerRole, "former@example.com", OwnerRole)
+	addFakeGroup(ac, gc, "stale@example.com", defaultSettings())
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit := &tenantAudit{log: NewAuditLog(path), base: AuditEntry{RunID: "run", ConfigCommit: "abc123", Tenant: "default"}}
+	log := discardLogger()
+	r := &Reconciler{
+		adminService: &adminService{client: &auditedAdminServiceClient{AdminServiceClient: ac, audit: audit}, log: log},
+		groupService: &groupService{client: &auditedGroupServiceClient{GroupServiceClient: gc, audit: audit}, log: log},
+		log:          log,
+	}
+
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+	groups := []GoogleGroup{{
+		EmailId: "team@example.com",
+		Owners:  []string{"owner@example.com"},
+		Members: []string{"new@example.com"},
+	}}
+	if err := r.ReconcileGroups(groups); err != nil {
+		t.Fatalf("unexpected error reconciling groups: %v", err)
+	}
+
+	entries, err := ReadAuditLog(path, func(AuditEntry) bool { return true })
+	if err != nil {
+		t.Fatalf("unexpected error reading audit log: %v", err)
+	}
+	var actions []string
+	for _, e := range entries {
+		if e.RunID != "run" || e.ConfigCommit != "abc123" || e.Tenant != "default" || e.Time.IsZero() {
+			t.Errorf("expected the entry to have the fields of the run, got %+v", e)
+		}
+		actions = append(actions, e.Action+" "+e.Group+" "+e.Member)
+	}
+	expected := []string{
+		"update_settings team@example.com ",
+		"add_member team@example.com new@example.com",
+		"remove_member team@example.com former@example.com",
+		"delete_group stale@example.com ",
+	}
+	if !reflect.DeepEqual(expected, actions) {
+		t.Errorf("unexpected audit entries:\nexpected %q\ngot      %q", expected, actions)
+	}
+
+	removed, err := ReadAuditLog(path, func(e AuditEntry) bool { return e.Member == "former@example.com" })
+	if err != nil {
+		t.Fatalf("unexpected error reading audit log: %v", err)
+	}
+	if len(removed) != 1 {
+		t.Fatalf("expected a single entry for the removed owner, got %v", removed)
+	}
+	var before admin.Member
+	if err := json.Unmarshal(removed[0].Before, &before); err != nil {
+		t.Fatalf("unexpected error decoding the state before the removal: %v", err)
+	}
+	if before.Role != OwnerRole || removed[0].After != nil {
+		t.Errorf("expected the removed owner to be recorded as an owner before and nothing after, got %s and %s", removed[0].Before, removed[0].After)
+	}
+}
@@ -18,6 +18,9 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	admin "google.golang.org/api/admin/directory/v1"
 	groupssettings "google.golang.org/api/groupssettings/v1"
@@ -37,17 +40,34 @@ type AdminServiceClient interface {
 	DeleteMember(groupKey, memberKey string) error
 }
 
-func NewAdminServiceClient(ctx context.Context, clientOption option.ClientOption) (AdminServiceClient, error) {
-	adminSvc, err := admin.NewService(ctx, clientOption)
+// NewAdminServiceClient returns an AdminServiceClient whose ListGroups lists
+// the groups of the given customer, or of each of the given domains if any.
+// If endpoint is not empty, it replaces https://www.googleapis.com/ as the
+// root URL of the API.
+func NewAdminServiceClient(ctx context.Context, customerID string, domains []string, endpoint string, clientOption option.ClientOption) (AdminServiceClient, error) {
+	adminSvc, err := admin.NewService(ctx, clientOptions(clientOption, endpoint, "admin/directory/v1/")...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &adminServiceClient{service: adminSvc}, nil
+	return &adminServiceClient{service: adminSvc, customerID: customerID, domains: domains}, nil
+}
+
+// clientOptions returns clientOption along with, if endpoint is not empty,
+// the option making the API at path, relative to https://www.googleapis.com/,
+// be called at the same path relative to endpoint.
+func clientOptions(clientOption option.ClientOption, endpoint, path string) []option.ClientOption {
+	opts := []option.ClientOption{clientOption}
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(strings.TrimSuffix(endpoint, "/")+"/"+path))
+	}
+	return opts
 }
 
 type adminServiceClient struct {
-	service *admin.Service
+	service    *admin.Service
+	customerID string
+	domains    []string
 }
 
 func (asc *adminServiceClient) GetGroup(groupKey string) (*admin.Group, error) {
@@ -59,7 +79,47 @@ func (asc *adminServiceClient) GetMember(groupKey, memberKey string) (*admin.Mem
 }
 
 func (asc *adminServiceClient) ListGroups() (*admin.Groups, error) {
-	return asc.service.Groups.List().Customer("my_customer").OrderBy("email").Do()
+	if len(asc.domains) == 0 {
+		return asc.listGroups("")
+	}
+
+	groups := &admin.Groups{}
+	for _, domain := range asc.domains {
+		g, err := asc.listGroups(domain)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list groups in domain %s: %w", domain, err)
+		}
+		groups.Groups = append(groups.Groups, g.Groups...)
+	}
+	sort.Slice(groups.Groups, func(i, j int) bool {
+		return groups.Groups[i].Email < groups.Groups[j].Email
+	})
+	return groups, nil
+}
+
+// listGroups lists all pages of the groups of the customer, restricted to
+// domain if it is not empty.
+func (asc *adminServiceClient) listGroups(domain string) (*admin.Groups, error) {
+	call := asc.service.Groups.List().OrderBy("email")
+	if asc.customerID != "" {
+		call = call.Customer(asc.customerID)
+	}
+	if domain != "" {
+		call = call.Domain(domain)
+	}
+
+	groups := &admin.Groups{}
+	for {
+		g, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		groups.Groups = append(groups.Groups, g.Groups...)
+		if g.NextPageToken == "" {
+			return groups, nil
+		}
+		call = call.PageToken(g.NextPageToken)
+	}
 }
 
 func (asc *adminServiceClient) ListMembers(groupKey string) (*admin.Members, error) {
@@ -97,8 +157,10 @@ type GroupServiceClient interface {
 	Patch(groupUniqueID string, groups *groupssettings.Groups) (*groupssettings.Groups, error)
 }
 
-func NewGroupServiceClient(ctx context.Context, clientOption option.ClientOption) (GroupServiceClient, error) {
-	groupSvc, err := groupssettings.NewService(ctx, clientOption)
+// NewGroupServiceClient returns a GroupServiceClient. If endpoint is not
+// empty, it replaces https://www.googleapis.com/ as the root URL of the API.
+func NewGroupServiceClient(ctx context.Context, endpoint string, clientOption option.ClientOption) (GroupServiceClient, error) {
+	groupSvc, err := groupssettings.NewService(ctx, clientOptions(clientOption, endpoint, "groups/v1/groups/")...)
 	if err != nil {
 		return nil, err
 	}
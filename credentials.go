@@ -0,0 +1,343 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	admin "google.golang.org/api/admin/directory/v1"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+	"google.golang.org/api/iamcredentials/v1"
+	"google.golang.org/api/option"
+	secretmanagerpb "google.golang.org/genproto/googleapis/cloud/secretmanager/v1"
+)
+
+const (
+	SecretManagerSource = "secret-manager"
+	KeyFileSource       = "key-file"
+	EnvSource           = "env"
+	ADCSource           = "adc"
+	SignJWTSource       = "sign-jwt"
+	TokenSource         = "token"
+	NoneSource          = "none"
+
+	// cloudPlatformScope is needed by the ambient credentials to call the
+	// IAM Credentials API.
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// Modes the tool runs in, each needing a different set of OAuth scopes.
+const (
+	// PrintMode only reads the live state of groups and prints it.
+	PrintMode = "print"
+	// PlanMode compares the live state with the config without changing it.
+	PlanMode = "plan"
+	// ApplyMode makes the live state match the config.
+	ApplyMode = "apply"
+)
+
+// ScopesForMode returns the least privileged set of OAuth scopes needed to
+// run in the given mode. Read-only modes get read-only variants of the Admin
+// Directory scopes; the Groups Settings API has no read-only scope, so its
+// only scope is always requested.
+func ScopesForMode(mode string) ([]string, error) {
+	switch mode {
+	case PrintMode, PlanMode:
+		return []string{
+			admin.AdminDirectoryGroupReadonlyScope,
+			admin.AdminDirectoryGroupMemberReadonlyScope,
+			groupssettings.AppsGroupsSettingsScope,
+		}, nil
+	case ApplyMode:
+		return []string{
+			admin.AdminDirectoryGroupScope,
+			admin.AdminDirectoryGroupMemberScope,
+			groupssettings.AppsGroupsSettingsScope,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown mode %q", mode)
+	}
+}
+
+// CredentialsConfig describes where the credentials used to talk to the
+// Google APIs come from.
+type CredentialsConfig struct {
+	// Source is one of secret-manager, key-file, env, adc, sign-jwt, token
+	// or none. If not specified, it defaults to secret-manager. The token
+	// and none sources don't call the token endpoint of Google, for use
+	// with an api-endpoint standing in for the Google APIs.
+	Source string `yaml:"source,omitempty"`
+
+	// KeyFile is the path to a service account key, used by the key-file source.
+	KeyFile string `yaml:"key-file,omitempty"`
+
+	// EnvVar is the name of the environment variable holding a service
+	// account key, used by the env source, or an access token, used by the
+	// token source.
+	EnvVar string `yaml:"env-var,omitempty"`
+
+	// TokenFile is the path to a file holding an access token, used by the
+	// token source instead of EnvVar.
+	TokenFile string `yaml:"token-file,omitempty"`
+
+	// ImpersonateServiceAccount is the email of a service account the
+	// Application Default Credentials impersonate, used by the adc source.
+	// If empty, the Application Default Credentials are used directly.
+	ImpersonateServiceAccount string `yaml:"impersonate-service-account,omitempty"`
+
+	// ServiceAccount is the email of the service account enabled for
+	// domain-wide delegation, used by the sign-jwt source. The delegated JWT
+	// is signed through the IAM Credentials API using the Application
+	// Default Credentials, so no key for it needs to exist.
+	ServiceAccount string `yaml:"service-account,omitempty"`
+}
+
+// CredentialProvider provides HTTP clients authorized to call the Google APIs.
+type CredentialProvider interface {
+	// Client returns a client authorized for scopes. Providers that support
+	// domain-wide delegation act on behalf of subject.
+	Client(ctx context.Context, subject string, scopes ...string) (*http.Client, error)
+}
+
+// NewCredentialProvider returns the CredentialProvider selected by the
+// credentials section of the tenant config.
+func NewCredentialProvider(c *Tenant) (CredentialProvider, error) {
+	switch c.Credentials.Source {
+	case SecretManagerSource, "":
+		if c.SecretVersion == "" {
+			return nil, fmt.Errorf("secret-version must be set for credentials source %q", SecretManagerSource)
+		}
+		return &keyCredentials{
+			description: fmt.Sprintf("secret-version %s", c.SecretVersion),
+			readKey: func(ctx context.Context) ([]byte, error) {
+				return accessSecretVersion(ctx, c.SecretVersion)
+			},
+		}, nil
+	case KeyFileSource:
+		if c.Credentials.KeyFile == "" {
+			return nil, fmt.Errorf("credentials.key-file must be set for credentials source %q", KeyFileSource)
+		}
+		return &keyCredentials{
+			description: fmt.Sprintf("key file %s", c.Credentials.KeyFile),
+			readKey: func(context.Context) ([]byte, error) {
+				return ioutil.ReadFile(c.Credentials.KeyFile)
+			},
+		}, nil
+	case EnvSource:
+		if c.Credentials.EnvVar == "" {
+			return nil, fmt.Errorf("credentials.env-var must be set for credentials source %q", EnvSource)
+		}
+		return &keyCredentials{
+			description: fmt.Sprintf("environment variable %s", c.Credentials.EnvVar),
+			readKey: func(context.Context) ([]byte, error) {
+				key, ok := os.LookupEnv(c.Credentials.EnvVar)
+				if !ok || key == "" {
+					return nil, fmt.Errorf("environment variable %s is not set", c.Credentials.EnvVar)
+				}
+				return []byte(key), nil
+			},
+		}, nil
+	case ADCSource:
+		return &adcCredentials{impersonate: c.Credentials.ImpersonateServiceAccount}, nil
+	case SignJWTSource:
+		if c.Credentials.ServiceAccount == "" {
+			return nil, fmt.Errorf("credentials.service-account must be set for credentials source %q", SignJWTSource)
+		}
+		return &signJWTCredentials{
+			serviceAccount: c.Credentials.ServiceAccount,
+			tokenURL:       google.JWTTokenURL,
+			newSigner:      NewIAMSigner,
+		}, nil
+	case TokenSource:
+		switch {
+		case c.Credentials.TokenFile != "":
+			return &tokenCredentials{
+				description: fmt.Sprintf("token file %s", c.Credentials.TokenFile),
+				readToken: func() ([]byte, error) {
+					return ioutil.ReadFile(c.Credentials.TokenFile)
+				},
+			}, nil
+		case c.Credentials.EnvVar != "":
+			return &tokenCredentials{
+				description: fmt.Sprintf("environment variable %s", c.Credentials.EnvVar),
+				readToken: func() ([]byte, error) {
+					return []byte(os.Getenv(c.Credentials.EnvVar)), nil
+				},
+			}, nil
+		default:
+			return nil, fmt.Errorf("credentials.token-file or credentials.env-var must be set for credentials source %q", TokenSource)
+		}
+	case NoneSource:
+		return noCredentials{}, nil
+	default:
+		return nil, fmt.Errorf("unknown credentials source %q", c.Credentials.Source)
+	}
+}
+
+// keyCredentials authenticates with a service account JSON key obtained from
+// readKey, using domain-wide delegation to act as the subject.
+type keyCredentials struct {
+	description string
+	readKey     func(ctx context.Context) ([]byte, error)
+}
+
+func (kc *keyCredentials) Client(ctx context.Context, subject string, scopes ...string) (*http.Client, error) {
+	key, err := kc.readKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read service account key from %s: %w", kc.description, err)
+	}
+
+	credential, err := google.JWTConfigFromJSON(key, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to authenticate using key in %s: %w", kc.description, err)
+	}
+	credential.Subject = subject
+
+	return credential.Client(ctx), nil
+}
+
+var _ CredentialProvider = (*keyCredentials)(nil)
+
+// tokenCredentials authenticates with a static access token obtained from
+// readToken, which is sent as is: the subject and the scopes are the ones
+// the token was issued for.
+type tokenCredentials struct {
+	description string
+	readToken   func() ([]byte, error)
+}
+
+func (tc *tokenCredentials) Client(ctx context.Context, _ string, _ ...string) (*http.Client, error) {
+	token, err := tc.readToken()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read access token from %s: %w", tc.description, err)
+	}
+	accessToken := strings.TrimSpace(string(token))
+	if accessToken == "" {
+		return nil, fmt.Errorf("no access token in %s", tc.description)
+	}
+	return oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken, TokenType: "Bearer"})), nil
+}
+
+var _ CredentialProvider = (*tokenCredentials)(nil)
+
+// noCredentials sends unauthenticated requests, for API stand-ins that don't
+// check them.
+type noCredentials struct{}
+
+func (noCredentials) Client(context.Context, string, ...string) (*http.Client, error) {
+	return &http.Client{}, nil
+}
+
+var _ CredentialProvider = noCredentials{}
+
+// adcCredentials authenticates with the Application Default Credentials,
+// optionally impersonating another service account through the IAM
+// Credentials API.
+//
+// When the Application Default Credentials are a service account key and no
+// impersonation is configured, domain-wide delegation is used to act as the
+// subject. Otherwise, the resulting identity must be granted access to the
+// Admin APIs directly.
+type adcCredentials struct {
+	impersonate string
+}
+
+func (ac *adcCredentials) Client(ctx context.Context, subject string, scopes ...string) (*http.Client, error) {
+	if ac.impersonate == "" {
+		creds, err := google.FindDefaultCredentials(ctx, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find default credentials: %w", err)
+		}
+		if credential, err := google.JWTConfigFromJSON(creds.JSON, scopes...); err == nil {
+			credential.Subject = subject
+			return credential.Client(ctx), nil
+		}
+		return oauth2.NewClient(ctx, creds.TokenSource), nil
+	}
+
+	ts, err := google.DefaultTokenSource(ctx, cloudPlatformScope)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find default credentials: %w", err)
+	}
+	iamService, err := iamcredentials.NewService(ctx, option.WithTokenSource(ts))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create iamcredentials client: %w", err)
+	}
+
+	its := &impersonatedTokenSource{
+		service:        iamService.Projects.ServiceAccounts,
+		serviceAccount: ac.impersonate,
+		scopes:         scopes,
+	}
+	return oauth2.NewClient(ctx, oauth2.ReuseTokenSource(nil, its)), nil
+}
+
+var _ CredentialProvider = (*adcCredentials)(nil)
+
+// impersonatedTokenSource mints access tokens for serviceAccount using the
+// generateAccessToken method of the IAM Credentials API.
+type impersonatedTokenSource struct {
+	service        *iamcredentials.ProjectsServiceAccountsService
+	serviceAccount string
+	scopes         []string
+}
+
+func (its *impersonatedTokenSource) Token() (*oauth2.Token, error) {
+	name := "projects/-/serviceAccounts/" + its.serviceAccount
+	resp, err := its.service.GenerateAccessToken(name, &iamcredentials.GenerateAccessTokenRequest{
+		Scope: its.scopes,
+	}).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to impersonate %s: %w", its.serviceAccount, err)
+	}
+
+	expiry, err := time.Parse(time.RFC3339, resp.ExpireTime)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse token expiry %q: %w", resp.ExpireTime, err)
+	}
+	return &oauth2.Token{AccessToken: resp.AccessToken, TokenType: "Bearer", Expiry: expiry}, nil
+}
+
+// accessSecretVersion accesses the payload for the given secret version if one exists
+// secretVersion is of the form projects/{project}/secrets/{secret}/versions/{version}
+func accessSecretVersion(ctx context.Context, secretVersion string) ([]byte, error) {
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secretmanager client: %w", err)
+	}
+	defer client.Close()
+
+	req := &secretmanagerpb.AccessSecretVersionRequest{
+		Name: secretVersion,
+	}
+
+	result, err := client.AccessSecretVersion(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access secret version: %w", err)
+	}
+
+	return result.Payload.Data, nil
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+const testServiceAccountKey = `{
+  "type": "service_account",
+  "client_email": "bot@example.iam.gserviceaccount.com",
+  "private_key_id": "1",
+  "private_key": "not-a-real-key",
+  "token_uri": "https://oauth2.googleapis.com/token"
+}`
+
+func TestNewCredentialProvider(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	if err := ioutil.WriteFile(keyFile, []byte(testServiceAccountKey), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("GGRECONCILE_TEST_KEY", testServiceAccountKey)
+	defer os.Unsetenv("GGRECONCILE_TEST_KEY")
+
+	testcases := []struct {
+		name        string
+		tenant      Tenant
+		expectNew   bool
+		expectReady bool
+	}{
+		{
+			name:   "secret manager without secret version",
+			tenant: Tenant{},
+		},
+		{
+			name:   "unknown source",
+			tenant: Tenant{Credentials: CredentialsConfig{Source: "vault"}},
+		},
+		{
+			name:        "key file",
+			tenant:      Tenant{Credentials: CredentialsConfig{Source: KeyFileSource, KeyFile: keyFile}},
+			expectNew:   true,
+			expectReady: true,
+		},
+		{
+			name:      "missing key file",
+			tenant:    Tenant{Credentials: CredentialsConfig{Source: KeyFileSource, KeyFile: keyFile + ".missing"}},
+			expectNew: true,
+		},
+		{
+			name:        "environment variable",
+			tenant:      Tenant{Credentials: CredentialsConfig{Source: EnvSource, EnvVar: "GGRECONCILE_TEST_KEY"}},
+			expectNew:   true,
+			expectReady: true,
+		},
+		{
+			name:      "unset environment variable",
+			tenant:    Tenant{Credentials: CredentialsConfig{Source: EnvSource, EnvVar: "GGRECONCILE_TEST_UNSET"}},
+			expectNew: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider, err := NewCredentialProvider(&tc.tenant)
+			if tc.expectNew != (err == nil) {
+				t.Fatalf("unexpected error creating provider: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			_, err = provider.Client(context.Background(), "bot@example.com", "scope")
+			if tc.expectReady != (err == nil) {
+				t.Errorf("unexpected error creating client: %v", err)
+			}
+		})
+	}
+}
+
+// fakeSigner is a JWTSigner that records the claims it is asked to sign and
+// returns them unsigned, prefixed with the signing service account.
+type fakeSigner struct {
+	claims jwtClaims
+}
+
+func (s *fakeSigner) SignJWT(_ context.Context, serviceAccount, payload string) (string, error) {
+	if err := json.Unmarshal([]byte(payload), &s.claims); err != nil {
+		return "", err
+	}
+	return serviceAccount + "." + payload, nil
+}
+
+func TestSignJWTCredentials(t *testing.T) {
+	var assertion string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("unable to parse token request: %v", err)
+		}
+		if grantType := r.PostForm.Get("grant_type"); grantType != jwtBearerGrantType {
+			t.Errorf("unexpected grant_type %q", grantType)
+		}
+		assertion = r.PostForm.Get("assertion")
+		fmt.Fprint(w, `{"access_token": "delegated-token", "token_type": "Bearer", "expires_in": 3600}`)
+	}))
+	defer server.Close()
+
+	signer := &fakeSigner{}
+	sc := &signJWTCredentials{
+		serviceAccount: "dwd@project.iam.gserviceaccount.com",
+		tokenURL:       server.URL,
+		newSigner: func(context.Context) (JWTSigner, error) {
+			return signer, nil
+		},
+	}
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer delegated-token" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+	}))
+	defer api.Close()
+
+	client, err := sc.Client(context.Background(), "bot@example.com", "scope-a", "scope-b")
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %v", err)
+	}
+	resp, err := client.Get(api.URL)
+	if err != nil {
+		t.Fatalf("unexpected error calling API: %v", err)
+	}
+	resp.Body.Close()
+
+	expected := jwtClaims{
+		Iss:   "dwd@project.iam.gserviceaccount.com",
+		Sub:   "bot@example.com",
+		Scope: "scope-a scope-b",
+		Aud:   server.URL,
+		Iat:   signer.claims.Iat,
+		Exp:   signer.claims.Iat + 3600,
+	}
+	if signer.claims != expected {
+		t.Errorf("unexpected claims: expected %+v, got %+v", expected, signer.claims)
+	}
+	if !strings.HasPrefix(assertion, "dwd@project.iam.gserviceaccount.com.") {
+		t.Errorf("unexpected assertion %q", assertion)
+	}
+}
+
+func TestAPIEndpoint(t *testing.T) {
+	var authorization []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/admin/directory/v1/groups/team@example.com":
+			fmt.Fprint(w, `{"email": "team@example.com", "name": "team"}`)
+		case "/groups/v1/groups/team@example.com":
+			fmt.Fprint(w, `{"email": "team@example.com", "whoCanJoin": "INVITED_CAN_JOIN"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv("GGRECONCILE_TEST_TOKEN", "static-token\n")
+	defer os.Unsetenv("GGRECONCILE_TEST_TOKEN")
+
+	for _, tc := range []struct {
+		credentials           CredentialsConfig
+		expectedAuthorization string
+	}{
+		{CredentialsConfig{Source: TokenSource, EnvVar: "GGRECONCILE_TEST_TOKEN"}, "Bearer static-token"},
+		{CredentialsConfig{Source: NoneSource}, ""},
+	} {
+		t.Run(tc.credentials.Source, func(t *testing.T) {
+			authorization = nil
+			tenant := &Tenant{Credentials: tc.credentials, APIEndpoint: server.URL}
+			provider, err := NewCredentialProvider(tenant)
+			if err != nil {
+				t.Fatalf("unexpected error creating provider: %v", err)
+			}
+			client, err := provider.Client(context.Background(), "bot@example.com", "scope")
+			if err != nil {
+				t.Fatalf("unexpected error creating client: %v", err)
+			}
+			clientOption := option.WithHTTPClient(client)
+
+			ac, err := NewAdminServiceClient(context.Background(), defaultCustomerID, nil, tenant.APIEndpoint, clientOption)
+			if err != nil {
+				t.Fatalf("unexpected error creating admin client: %v", err)
+			}
+			g, err := ac.GetGroup("team@example.com")
+			if err != nil || g.Name != "team" {
+				t.Errorf("unexpected group %+v, %v", g, err)
+			}
+			gc, err := NewGroupServiceClient(context.Background(), tenant.APIEndpoint, clientOption)
+			if err != nil {
+				t.Fatalf("unexpected error creating settings client: %v", err)
+			}
+			settings, err := gc.Get("team@example.com")
+			if err != nil || settings.WhoCanJoin != "INVITED_CAN_JOIN" {
+				t.Errorf("unexpected settings %+v, %v", settings, err)
+			}
+
+			for _, a := range authorization {
+				if a != tc.expectedAuthorization {
+					t.Errorf("expected Authorization header %q, got %q", tc.expectedAuthorization, a)
+				}
+			}
+			if len(authorization) != 2 {
+				t.Errorf("expected 2 requests to the endpoint, got %d", len(authorization))
+			}
+		})
+	}
+
+	if _, err := NewCredentialProvider(&Tenant{Credentials: CredentialsConfig{Source: TokenSource}}); err == nil {
+		t.Error("expected an error for a token source without token")
+	}
+}
+
+func TestScopesForMode(t *testing.T) {
+	for _, mode := range []string{PrintMode, PlanMode} {
+		scopes, err := ScopesForMode(mode)
+		if err != nil {
+			t.Fatalf("unexpected error for mode %s: %v", mode, err)
+		}
+		for _, scope := range scopes {
+			if strings.HasPrefix(scope, "https://www.googleapis.com/auth/admin.") && !strings.HasSuffix(scope, ".readonly") {
+				t.Errorf("mode %s requests writable scope %s", mode, scope)
+			}
+		}
+	}
+
+	if _, err := ScopesForMode("unknown"); err == nil {
+		t.Errorf("expected an error for an unknown mode")
+	}
+}
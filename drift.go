@@ -0,0 +1,238 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DriftExitCode is the exit code of the drift command when the live state
+// of the groups differs from the config.
+const DriftExitCode = 3
+
+// Kinds of drift between the live state of the groups and the config.
+const (
+	// MembershipDrift is a member missing from a group, or present in it
+	// without being in the config while the group's members are reconciled.
+	MembershipDrift = "membership"
+	// RoleDrift is a member holding a different role than in the config.
+	RoleDrift = "role"
+	// SettingsDrift is a setting, name or description differing from the config.
+	SettingsDrift = "settings"
+	// UnmanagedGroupDrift is a group that exists but is not in the config.
+	UnmanagedGroupDrift = "unmanaged-group"
+	// MissingGroupDrift is a group in the config that doesn't exist.
+	MissingGroupDrift = "missing-group"
+)
+
+// driftKinds lists the kinds of drift in the order they are reported.
+var driftKinds = []string{MembershipDrift, RoleDrift, SettingsDrift, UnmanagedGroupDrift, MissingGroupDrift}
+
+// Drift is a difference between the live state of a group and the config.
+type Drift struct {
+	Kind   string
+	Group  string
+	Detail string
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("[%s] %s: %s", d.Kind, d.Group, d.Detail)
+}
+
+// DetectDrift compares the live state of the groups with groups, the config,
+// without changing anything. It reports the differences that ReconcileGroups
+// would undo, along with the groups it would delete.
+func (r *Reconciler) DetectDrift(groups []GoogleGroup) ([]Drift, error) {
+	live, err := r.liveGroups()
+	if err != nil {
+		return nil, err
+	}
+	liveByEmail := make(map[string]GoogleGroup, len(live))
+	for _, g := range live {
+		liveByEmail[strings.ToLower(g.EmailId)] = g
+	}
+
+	var drifts []Drift
+	now := time.Now()
+	configured := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		g = g.withoutExpiredMembers(now)
+		configured[strings.ToLower(g.EmailId)] = true
+		l, ok := liveByEmail[strings.ToLower(g.EmailId)]
+		if !ok {
+			drifts = append(drifts, Drift{MissingGroupDrift, g.EmailId, "group does not exist"})
+			continue
+		}
+		drifts = append(drifts, groupDrift(g, l)...)
+	}
+
+	for _, l := range live {
+		if !configured[strings.ToLower(l.EmailId)] {
+			drifts = append(drifts, Drift{UnmanagedGroupDrift, l.EmailId, "group is not in the config"})
+		}
+	}
+	return drifts, nil
+}
+
+// groupDrift compares want, a group in the config, with have, the live state
+// of the same group as returned by liveGroups.
+func groupDrift(want, have GoogleGroup) []Drift {
+	var drifts []Drift
+	add := func(kind, format string, args ...interface{}) {
+		drifts = append(drifts, Drift{kind, want.EmailId, fmt.Sprintf(format, args...)})
+	}
+
+	if want.Name != "" && want.Name != have.Name {
+		add(SettingsDrift, "name is %q, want %q", have.Name, want.Name)
+	}
+	if want.Description != "" && want.Description != have.Description {
+		add(SettingsDrift, "description is %q, want %q", have.Description, want.Description)
+	}
+
+	for _, setting := range groupSettings {
+		wantValue, ok := want.Settings[setting.Name]
+		if !ok {
+			if setting.Default == "" {
+				continue
+			}
+			wantValue = setting.Default
+		}
+		haveValue, ok := have.Settings[setting.Name]
+		if !ok {
+			haveValue = setting.Default
+			if haveValue == "" {
+				haveValue = setting.Initial
+			}
+		}
+		if haveValue != wantValue {
+			add(SettingsDrift, "%s is %q, want %q", setting.Name, haveValue, wantValue)
+		}
+	}
+
+	policy := want.membershipPolicy()
+	if policy == IgnorePolicy {
+		return drifts
+	}
+
+	ignored := ignoredMembers(want)
+	wantRoles := memberRoles(want)
+	haveRoles := memberRoles(have)
+	for _, email := range sortedKeys(wantRoles) {
+		haveRole, ok := haveRoles[email]
+		switch {
+		case ignored(email):
+		case !ok:
+			add(MembershipDrift, "%s is missing as %s", email, wantRoles[email])
+		case haveRole != wantRoles[email]:
+			add(RoleDrift, "%s is %s, want %s", email, haveRole, wantRoles[email])
+		}
+	}
+
+	for _, email := range sortedKeys(haveRoles) {
+		if _, ok := wantRoles[email]; ok || ignored(email) {
+			continue
+		}
+		if policy == AuthoritativePolicy || policy == OwnersAuthoritativePolicy && haveRoles[email] != MemberRole {
+			add(MembershipDrift, "%s is an unexpected %s", email, haveRoles[email])
+		}
+	}
+
+	return drifts
+}
+
+// memberRoles maps the lowercased email of each member of g to its role, as
+// emails are case-insensitive.
+func memberRoles(g GoogleGroup) map[string]string {
+	roles := map[string]string{}
+	for _, m := range g.Members {
+		roles[strings.ToLower(m)] = MemberRole
+	}
+	for _, m := range g.Managers {
+		roles[strings.ToLower(m)] = ManagerRole
+	}
+	for _, m := range g.Owners {
+		roles[strings.ToLower(m)] = OwnerRole
+	}
+	return roles
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// summarizeDrift returns a one line summary of drifts, counted by kind.
+func summarizeDrift(drifts []Drift) string {
+	if len(drifts) == 0 {
+		return "no drift"
+	}
+	counts := map[string]int{}
+	for _, d := range drifts {
+		counts[d.Kind]++
+	}
+	var parts []string
+	for _, kind := range driftKinds {
+		if counts[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", kind, counts[kind]))
+		}
+	}
+	return fmt.Sprintf("%d drifts (%s)", len(drifts), strings.Join(parts, ", "))
+}
+
+// driftCommand registers the flags of the drift command, which reports how
+// the live state of the groups differs from the config without changing it.
+func driftCommand() func(configFilePath string) error {
+	return func(configFilePath string) error {
+		if err := loadConfig(configFilePath, false); err != nil {
+			return err
+		}
+
+		drifted := false
+		err := forEachTenant(PlanMode, func(t *Tenant, r *Reconciler) (string, error) {
+			groupsConfig, err := loadTenantGroups(t)
+			if err != nil {
+				return "", err
+			}
+
+			drifts, err := r.DetectDrift(groupsConfig.Groups)
+			if err != nil {
+				return "", err
+			}
+			for _, d := range drifts {
+				fmt.Printf("%s: %s\n", t.Name, d)
+			}
+			if len(drifts) > 0 {
+				drifted = true
+			}
+			return summarizeDrift(drifts), nil
+		})
+		if err != nil {
+			return err
+		}
+		if drifted {
+			return &exitCodeError{code: DriftExitCode, err: fmt.Errorf("drift detected between the live groups and the config")}
+		}
+		return nil
+	}
+}
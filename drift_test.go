@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDetectDrift(t *testing.T) {
+	r, ac, gc := newFakeReconciler()
+	settings := defaultSettings()
+	settings.WhoCanJoin = "ANYONE_CAN_JOIN"
+	addFakeGroup(ac, gc, "team@example.com", settings,
+		"owner@example.com", OwnerRole,
+		"promoted@example.com", OwnerRole,
+		"extra@example.com", MemberRole,
+		"manager@example.com", ManagerRole)
+	addFakeGroup(ac, gc, "reconciled@example.com", defaultSettings(),
+		"owner@example.com", OwnerRole,
+		"extra@example.com", MemberRole)
+	addFakeGroup(ac, gc, "handmade@example.com", defaultSettings())
+
+	groups := []GoogleGroup{
+		{
+			EmailId:  "team@example.com",
+			Name:     "renamed",
+			Owners:   []string{"owner@example.com"},
+			Managers: []string{"promoted@example.com"},
+			Members:  []string{"new@example.com"},
+		},
+		{
+			EmailId:  "reconciled@example.com",
+			Name:     "reconciled",
+			Settings: map[string]string{"ReconcileMembers": "true"},
+			Owners:   []string{"owner@example.com"},
+		},
+		{EmailId: "missing@example.com"},
+	}
+
+	drifts, err := r.DetectDrift(groups)
+	if err != nil {
+		t.Fatalf("unexpected error detecting drift: %v", err)
+	}
+
+	expected := []Drift{
+		{SettingsDrift, "team@example.com", `name is "team", want "renamed"`},
+		{SettingsDrift, "team@example.com", `WhoCanJoin is "ANYONE_CAN_JOIN", want "INVITED_CAN_JOIN"`},
+		{MembershipDrift, "team@example.com", "new@example.com is missing as MEMBER"},
+		{RoleDrift, "team@example.com", "promoted@example.com is OWNER, want MANAGER"},
+		{MembershipDrift, "team@example.com", "manager@example.com is an unexpected MANAGER"},
+		{MembershipDrift, "reconciled@example.com", "extra@example.com is an unexpected MEMBER"},
+		{MissingGroupDrift, "missing@example.com", "group does not exist"},
+		{UnmanagedGroupDrift, "handmade@example.com", "group is not in the config"},
+	}
+	if !reflect.DeepEqual(expected, drifts) {
+		t.Errorf("unexpected drift:\nexpected %v\ngot      %v", expected, drifts)
+	}
+	if len(ac.calls) > 0 || len(gc.calls) > 0 {
+		t.Errorf("expected no changes while detecting drift, got %v %v", ac.calls, gc.calls)
+	}
+
+	summary := summarizeDrift(drifts)
+	if expected := "8 drifts (membership: 3, role: 1, settings: 2, unmanaged-group: 1, missing-group: 1)"; summary != expected {
+		t.Errorf("unexpected summary: expected %q, got %q", expected, summary)
+	}
+}
+
+func TestDetectNoDrift(t *testing.T) {
+	r, ac, gc := newFakeReconciler()
+	settings := defaultSettings()
+	settings.AllowWebPosting = "false"
+	addFakeGroup(ac, gc, "team@example.com", settings, "owner@example.com", OwnerRole, "member@example.com", MemberRole)
+
+	live, err := r.liveGroups()
+	if err != nil {
+		t.Fatalf("unexpected error listing groups: %v", err)
+	}
+	drifts, err := r.DetectDrift(live)
+	if err != nil {
+		t.Fatalf("unexpected error detecting drift: %v", err)
+	}
+	if len(drifts) > 0 {
+		t.Errorf("expected no drift, got %v", drifts)
+	}
+}
+
+func TestDetectDriftEmailCase(t *testing.T) {
+	r, ac, gc := newFakeReconciler()
+	addFakeGroup(ac, gc, "team@example.com", defaultSettings(), "owner@example.com", OwnerRole, "member@example.com", MemberRole)
+	groups := []GoogleGroup{{
+		EmailId:          "Team@example.com",
+		MembershipPolicy: AuthoritativePolicy,
+		Owners:           []string{"Owner@example.com"},
+		Members:          []string{"MEMBER@example.com"},
+	}}
+
+	drifts, err := r.DetectDrift(groups)
+	if err != nil {
+		t.Fatalf("unexpected error detecting drift: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("expected no drift for emails differing only in case, got %v", drifts)
+	}
+	if p := fullApplyPlan(groups); p.hasChanges([]GoogleGroup{{EmailId: "team@example.com", Owners: []string{"owner@example.com"}, Members: []string{"member@example.com"}}}, time.Now()) {
+		t.Error("expected no change to snapshot for emails differing only in case")
+	}
+}
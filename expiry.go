@@ -0,0 +1,282 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// expiryDateFormat is the format of the expiry dates of memberships.
+const expiryDateFormat = "2006-01-02"
+
+// defaultExpiryWarningDays is how many days before they expire memberships
+// are warned about when expiry-warning-days is not set.
+const defaultExpiryWarningDays = 14
+
+// memberEntry is an owner, manager or member of a group in the groups config.
+// It is either an email or a mapping with an email and an expiry date:
+//
+//	members:
+//	  - alice@example.com
+//	  - email: bob@example.com
+//	    expires: 2026-12-31
+type memberEntry struct {
+	Email   string `yaml:"email" json:"email"`
+	Expires string `yaml:"expires,omitempty" json:"expires,omitempty"`
+}
+
+func (m *memberEntry) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&m.Email)
+	}
+	type plain memberEntry
+	return node.Decode((*plain)(m))
+}
+
+func (m *memberEntry) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &m.Email); err == nil {
+		return nil
+	}
+	type plain memberEntry
+	return json.Unmarshal(b, (*plain)(m))
+}
+
+// memberLists holds the owners, managers and members of a group as they are
+// written in the groups config.
+type memberLists struct {
+	Owners   []memberEntry `yaml:"owners"`
+	Managers []memberEntry `yaml:"managers"`
+	Members  []memberEntry `yaml:"members"`
+}
+
+// isMemberListKey reports whether key holds one of the memberLists.
+func isMemberListKey(key string) bool {
+	return key == "owners" || key == "managers" || key == "members"
+}
+
+// plainGoogleGroup is a GoogleGroup decoded without expiry dates.
+type plainGoogleGroup GoogleGroup
+
+// UnmarshalYAML decodes the owners, managers and members of the group, which
+// may have an expiry date, separately from its other fields.
+func (g *GoogleGroup) UnmarshalYAML(node *yaml.Node) error {
+	var lists memberLists
+	if err := node.Decode(&lists); err != nil {
+		return err
+	}
+	rest := *node
+	rest.Content = nil
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !isMemberListKey(node.Content[i].Value) {
+			rest.Content = append(rest.Content, node.Content[i], node.Content[i+1])
+		}
+	}
+	if err := rest.Decode((*plainGoogleGroup)(g)); err != nil {
+		return err
+	}
+	return g.setMembers(lists)
+}
+
+// UnmarshalJSON is the JSON counterpart of UnmarshalYAML.
+func (g *GoogleGroup) UnmarshalJSON(b []byte) error {
+	var lists memberLists
+	if err := json.Unmarshal(b, &lists); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	for key := range fields {
+		if isMemberListKey(key) {
+			delete(fields, key)
+		}
+	}
+	rest, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(rest, (*plainGoogleGroup)(g)); err != nil {
+		return err
+	}
+	return g.setMembers(lists)
+}
+
+// UnmarshalTOML is the TOML counterpart of UnmarshalYAML. The TOML and JSON
+// keys of GoogleGroup are the same, so data is decoded as JSON.
+func (g *GoogleGroup) UnmarshalTOML(data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return g.UnmarshalJSON(b)
+}
+
+// setMembers sets the owners, managers and members of g from lists, along
+// with their expiry dates.
+func (g *GoogleGroup) setMembers(lists memberLists) error {
+	g.Expires = nil
+	emails := func(role string, entries []memberEntry) ([]string, error) {
+		if entries == nil {
+			return nil, nil
+		}
+		list := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if e.Email == "" {
+				return nil, fmt.Errorf("group %q has a member entry with no email", g.EmailId)
+			}
+			list = append(list, e.Email)
+			if e.Expires == "" {
+				continue
+			}
+			expires, err := parseExpiryDate(e.Expires)
+			if err != nil {
+				return nil, fmt.Errorf("group %q member %s: %w", g.EmailId, e.Email, err)
+			}
+			if g.Expires == nil {
+				g.Expires = map[string]time.Time{}
+			}
+			g.Expires[expiryKey(role, e.Email)] = expires
+		}
+		return list, nil
+	}
+
+	var err error
+	if g.Owners, err = emails(OwnerRole, lists.Owners); err != nil {
+		return err
+	}
+	if g.Managers, err = emails(ManagerRole, lists.Managers); err != nil {
+		return err
+	}
+	g.Members, err = emails(MemberRole, lists.Members)
+	return err
+}
+
+// expiryKey is the key in GoogleGroup.Expires of the membership of email with
+// role. Emails are lowercased as they are case-insensitive.
+func expiryKey(role, email string) string {
+	return role + " " + strings.ToLower(email)
+}
+
+// expiringMembership is a membership given an expiry date in the config.
+type expiringMembership struct {
+	Role    string
+	Email   string
+	Expires time.Time
+}
+
+// expiringMemberships returns the memberships of g with an expiry date for
+// which match returns true, sorted by email.
+func (g GoogleGroup) expiringMemberships(match func(expires time.Time) bool) []expiringMembership {
+	var memberships []expiringMembership
+	for _, list := range []struct {
+		role    string
+		members []string
+	}{{OwnerRole, g.Owners}, {ManagerRole, g.Managers}, {MemberRole, g.Members}} {
+		for _, email := range list.members {
+			expires, ok := g.Expires[expiryKey(list.role, email)]
+			if ok && match(expires) {
+				memberships = append(memberships, expiringMembership{Role: list.role, Email: email, Expires: expires})
+			}
+		}
+	}
+	sort.SliceStable(memberships, func(i, j int) bool {
+		return memberships[i].Email < memberships[j].Email
+	})
+	return memberships
+}
+
+// parseExpiryDate parses an expiry date, either as a date or as a timestamp
+// whose time is ignored, as TOML dates are turned into when decoded.
+func parseExpiryDate(s string) (time.Time, error) {
+	t, err := time.Parse(expiryDateFormat, s)
+	if err != nil {
+		ts, tsErr := time.Parse(time.RFC3339, s)
+		if tsErr != nil {
+			return time.Time{}, fmt.Errorf("invalid expiry date %q, expected YYYY-MM-DD", s)
+		}
+		t = time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t, nil
+}
+
+// membershipExpired reports whether a membership expiring on the date expires
+// is expired at now. Memberships last until the end of their expiry date, in
+// UTC.
+func membershipExpired(expires, now time.Time) bool {
+	return !now.Before(expires.AddDate(0, 0, 1))
+}
+
+// expiredMembers returns the memberships of g that are expired at now.
+func (g GoogleGroup) expiredMembers(now time.Time) []expiringMembership {
+	return g.expiringMemberships(func(expires time.Time) bool {
+		return membershipExpired(expires, now)
+	})
+}
+
+// expiringMembers returns the memberships of g that are not expired at now
+// but expire within days.
+func (g GoogleGroup) expiringMembers(now time.Time, days int) []expiringMembership {
+	return g.expiringMemberships(func(expires time.Time) bool {
+		return !membershipExpired(expires, now) && membershipExpired(expires, now.AddDate(0, 0, days))
+	})
+}
+
+// withoutExpiredMembers returns g without the owners, managers and members
+// whose membership is expired at now, as they are treated as absent. Only
+// the expired role is dropped for an email listed with several roles.
+func (g GoogleGroup) withoutExpiredMembers(now time.Time) GoogleGroup {
+	if len(g.expiredMembers(now)) == 0 {
+		return g
+	}
+	active := func(role string, emails []string) []string {
+		var list []string
+		for _, email := range emails {
+			if expires, ok := g.Expires[expiryKey(role, email)]; !ok || !membershipExpired(expires, now) {
+				list = append(list, email)
+			}
+		}
+		return list
+	}
+	g.Owners = active(OwnerRole, g.Owners)
+	g.Managers = active(ManagerRole, g.Managers)
+	g.Members = active(MemberRole, g.Members)
+	return g
+}
+
+// logExpiringMembers logs the memberships of g that are expired at now, and
+// warns about the ones expiring within days.
+func logExpiringMembers(log *logrus.Entry, g GoogleGroup, now time.Time, days int) {
+	for _, m := range g.expiredMembers(now) {
+		log.WithFields(logrus.Fields{groupField: g.EmailId, memberField: m.Email, roleField: m.Role, "expires": m.Expires.Format(expiryDateFormat)}).
+			Info("membership expired, treating the member as absent")
+	}
+	if days <= 0 {
+		return
+	}
+	for _, m := range g.expiringMembers(now, days) {
+		log.WithFields(logrus.Fields{groupField: g.EmailId, memberField: m.Email, roleField: m.Role, "expires": m.Expires.Format(expiryDateFormat)}).
+			Warn("membership expires soon")
+	}
+}
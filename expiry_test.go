@@ -0,0 +1,153 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemberExpiry(t *testing.T) {
+	now := time.Now().UTC()
+	today := now.Format(expiryDateFormat)
+	yesterday := now.AddDate(0, 0, -1).Format(expiryDateFormat)
+	soon := now.AddDate(0, 0, 3).Format(expiryDateFormat)
+
+	rootDir := t.TempDir()
+	files := map[string]string{
+		"groups.yaml": `groups:
+  - email-id: yaml@example.com
+    owners: [owner@example.com]
+    members:
+      - member@example.com
+      - email: expired@example.com
+        expires: ` + yesterday + `
+      - email: today@example.com
+        expires: "` + today + `"
+    settings:
+      ReconcileMembers: "true"
+`,
+		"json/groups.json": `{"groups": [{"email-id": "json@example.com", "managers": ["m@example.com", {"email": "soon@example.com", "expires": "` + soon + `"}]}]}`,
+		"toml/groups.toml": "[[groups]]\nemail-id = \"toml@example.com\"\nmembers = [\"a@example.com\", {email = \"b@example.com\", expires = " + soon + "}]\n",
+	}
+	writeTree(t, rootDir, files)
+
+	var gc GroupsConfig
+	opts := GroupsLoadOptions{Patterns: []string{"groups.yaml", "groups.json", "groups.toml"}}
+	if err := gc.Load(rootDir, opts, &RestrictionsConfig{}); err != nil {
+		t.Fatalf("unexpected error loading groups: %v", err)
+	}
+	got := map[string]GoogleGroup{}
+	for _, g := range gc.Groups {
+		got[g.EmailId] = g
+	}
+
+	yamlGroup := got["yaml@example.com"]
+	if expected := []string{"member@example.com", "expired@example.com", "today@example.com"}; !reflect.DeepEqual(expected, yamlGroup.Members) {
+		t.Errorf("unexpected members: expected %v, got %v", expected, yamlGroup.Members)
+	}
+	expiredDate, _ := parseExpiryDate(yesterday)
+	if expected := []expiringMembership{{Role: MemberRole, Email: "expired@example.com", Expires: expiredDate}}; !reflect.DeepEqual(expected, yamlGroup.expiredMembers(now)) {
+		t.Errorf("unexpected expired members: expected %v, got %v", expected, yamlGroup.expiredMembers(now))
+	}
+	soonDate, _ := parseExpiryDate(soon)
+	if expected := []expiringMembership{{Role: ManagerRole, Email: "soon@example.com", Expires: soonDate}}; !reflect.DeepEqual(expected, got["json@example.com"].expiringMembers(now, 7)) {
+		t.Errorf("unexpected expiring managers: expected %v, got %v", expected, got["json@example.com"].expiringMembers(now, 7))
+	}
+	if expires := got["toml@example.com"].Expires[expiryKey(MemberRole, "b@example.com")].Format(expiryDateFormat); expires != soon {
+		t.Errorf("unexpected expiry date of a toml member: expected %s, got %s", soon, expires)
+	}
+	if expiring := got["toml@example.com"].expiringMembers(now, 2); len(expiring) != 0 {
+		t.Errorf("expected no membership expiring within 2 days, got %v", expiring)
+	}
+
+	err := validateGroups(gc.Groups, now)
+	if err == nil || !strings.Contains(err.Error(), "expired@example.com expired on "+yesterday) {
+		t.Errorf("expected validation to fail on the expired membership, got %v", err)
+	}
+
+	// Expired members are treated as absent.
+	r, ac, gsc := newFakeReconciler()
+	addFakeGroup(ac, gsc, "yaml@example.com", defaultSettings(),
+		"owner@example.com", OwnerRole, "member@example.com", MemberRole, "expired@example.com", MemberRole)
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+	if err := r.ReconcileSelectedGroups(gc.Groups, GroupFilter{Groups: []string{"yaml@example.com"}}, false); err != nil {
+		t.Fatalf("unexpected error reconciling groups: %v", err)
+	}
+	expected := []string{
+		"InsertMember yaml@example.com today@example.com MEMBER",
+		"DeleteMember yaml@example.com expired@example.com",
+	}
+	if !reflect.DeepEqual(expected, ac.calls) {
+		t.Errorf("unexpected calls:\nexpected %v\ngot      %v", expected, ac.calls)
+	}
+
+	var bad GroupsConfig
+	if err := unmarshalGroupsConfig("groups.yaml", []byte("groups:\n  - email-id: a@example.com\n    members:\n      - email: b@example.com\n        expires: next week\n"), &bad); err == nil {
+		t.Errorf("expected an error for an invalid expiry date")
+	}
+}
+
+func TestMemberExpiryRolesAndCase(t *testing.T) {
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1).Format(expiryDateFormat)
+
+	var gc GroupsConfig
+	content := `groups:
+  - email-id: team@example.com
+    owners:
+      - alice@example.com
+    members:
+      - email: alice@example.com
+        expires: ` + yesterday + `
+      - email: Bob@example.com
+        expires: ` + yesterday + `
+    settings:
+      ReconcileMembers: "true"
+`
+	if err := unmarshalGroupsConfig("groups.yaml", []byte(content), &gc); err != nil {
+		t.Fatalf("unexpected error parsing groups: %v", err)
+	}
+
+	// The expired membership of alice only drops her member role, not her
+	// owner one, and the expiry of Bob applies to the live bob.
+	g := gc.Groups[0].withoutExpiredMembers(now)
+	if expected := []string{"alice@example.com"}; !reflect.DeepEqual(expected, g.Owners) {
+		t.Errorf("unexpected owners: expected %v, got %v", expected, g.Owners)
+	}
+	if len(g.Members) != 0 {
+		t.Errorf("expected no active members, got %v", g.Members)
+	}
+
+	r, ac, gsc := newFakeReconciler()
+	addFakeGroup(ac, gsc, "team@example.com", defaultSettings(),
+		"alice@example.com", OwnerRole, "bob@example.com", MemberRole)
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+	if err := r.ReconcileSelectedGroups(gc.Groups, GroupFilter{Groups: []string{"team@example.com"}}, false); err != nil {
+		t.Fatalf("unexpected error reconciling groups: %v", err)
+	}
+	if expected := []string{"DeleteMember team@example.com bob@example.com"}; !reflect.DeepEqual(expected, ac.calls) {
+		t.Errorf("unexpected calls:\nexpected %v\ngot      %v", expected, ac.calls)
+	}
+	if m := whois("ALICE@example.com", gc.Groups); len(m) != 1 || m[0].Role != OwnerRole || !m[0].Expires.IsZero() {
+		t.Errorf("expected alice to be an owner without expiry, got %+v", m)
+	}
+}
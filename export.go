@@ -0,0 +1,234 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// exportHeader is written at the top of every file generated by export.
+const exportHeader = "# Generated by ggreconcile export from the live state of the groups.\n"
+
+// ExportMappingConfig assigns the exported groups to directories of the
+// generated groups tree.
+type ExportMappingConfig struct {
+	Mappings []ExportMapping `yaml:"mappings,omitempty"`
+}
+
+type ExportMapping struct {
+	// Path is the directory, relative to the output directory, the groups
+	// matching Groups are written to.
+	Path string `yaml:"path"`
+	// Groups is the list of regular expressions for email-ids of the groups
+	// written to Path. They also become the allowedGroups of the generated
+	// restriction for Path.
+	//
+	// Compiles to GroupsRe during config load.
+	Groups []string `yaml:"groups"`
+
+	GroupsRe []*regexp.Regexp `yaml:"-"`
+}
+
+// Load populates the ExportMappingConfig with data parsed from path and
+// returns nil if successful, or an error otherwise
+func (mc *ExportMappingConfig) Load(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading export mapping file %s: %w", path, err)
+	}
+	if err = yaml.Unmarshal(content, mc); err != nil {
+		return fmt.Errorf("error parsing export mapping file %s: %w", path, err)
+	}
+
+	for i := range mc.Mappings {
+		m := &mc.Mappings[i]
+		if m.Path == "" || filepath.IsAbs(m.Path) || strings.HasPrefix(filepath.Clean(m.Path), "..") {
+			return fmt.Errorf("export mapping path must be a relative path inside the output directory, got: %q", m.Path)
+		}
+		for _, g := range m.Groups {
+			re, err := regexp.Compile(g)
+			if err != nil {
+				return fmt.Errorf("error parsing group pattern %q for path %q: %w", g, m.Path, err)
+			}
+			m.GroupsRe = append(m.GroupsRe, re)
+		}
+	}
+	return nil
+}
+
+// groupPlacer returns the directory, relative to the output directory, a group
+// is exported to, along with the pattern allowing it there. An empty
+// directory means the root of the output directory.
+type groupPlacer func(emailId string) (dir, pattern string)
+
+// placeByPrefix places each group in a directory named after the part of its
+// email-id before the first separator. Groups without a separator are placed
+// at the root.
+func placeByPrefix(separator string) groupPlacer {
+	return func(emailId string) (string, string) {
+		local := emailId
+		if at := strings.LastIndex(emailId, "@"); at >= 0 {
+			local = emailId[:at]
+		}
+		i := strings.Index(local, separator)
+		if separator == "" || i <= 0 {
+			return "", ""
+		}
+		return local[:i], "^" + regexp.QuoteMeta(local[:i+len(separator)])
+	}
+}
+
+// placeByMapping places each group in the path of the first mapping matching
+// it. Groups without a matching mapping are placed at the root.
+func placeByMapping(mc *ExportMappingConfig) groupPlacer {
+	return func(emailId string) (string, string) {
+		for _, m := range mc.Mappings {
+			for i, re := range m.GroupsRe {
+				if re.MatchString(emailId) {
+					return filepath.Clean(m.Path), m.Groups[i]
+				}
+			}
+		}
+		return "", ""
+	}
+}
+
+// exportCommand registers the flags of the export command, which bootstraps
+// a groups tree from the live state of the groups.
+func exportCommand() func(configFilePath string) error {
+	outDir := flag.String("out", "", "the directory to write the groups tree to; it must not exist or be empty")
+	separator := flag.String("prefix-separator", "-", "group email-ids are placed in a directory named after the part before this separator")
+	mappingPath := flag.String("mapping", "", "a file mapping regular expressions of email-ids to directories, used instead of -prefix-separator")
+
+	return func(configFilePath string) error {
+		if *outDir == "" {
+			return fmt.Errorf("export: -out must be specified")
+		}
+
+		place := placeByPrefix(*separator)
+		if *mappingPath != "" {
+			var mc ExportMappingConfig
+			if err := mc.Load(*mappingPath); err != nil {
+				return err
+			}
+			place = placeByMapping(&mc)
+		}
+
+		if err := loadConfig(configFilePath, false); err != nil {
+			return err
+		}
+
+		return forEachTenant(PrintMode, func(t *Tenant, r *Reconciler) (string, error) {
+			dir := *outDir
+			if len(config.Tenants) > 1 {
+				dir = filepath.Join(dir, t.Name)
+			}
+
+			groups, err := r.liveGroups()
+			if err != nil {
+				return "", err
+			}
+			if err := writeGroupsTree(dir, groups, place); err != nil {
+				return "", err
+			}
+			logger.WithField("path", dir).Infof("exported %d groups", len(groups))
+			return fmt.Sprintf("%d groups exported", len(groups)), nil
+		})
+	}
+}
+
+// writeGroupsTree writes groups to one groups.yaml file per directory chosen
+// by place under outDir, along with a restrictions.yaml file allowing each
+// group to be defined where it was written.
+func writeGroupsTree(outDir string, groups []GoogleGroup, place groupPlacer) error {
+	if entries, err := os.ReadDir(outDir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("output directory %s is not empty", outDir)
+	}
+
+	byDir := map[string][]GoogleGroup{}
+	patterns := map[string]map[string]bool{}
+	for _, g := range groups {
+		dir, pattern := place(g.EmailId)
+		byDir[dir] = append(byDir[dir], g)
+		if dir == "" {
+			continue
+		}
+		if patterns[dir] == nil {
+			patterns[dir] = map[string]bool{}
+		}
+		patterns[dir][pattern] = true
+	}
+
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var restrictions RestrictionsConfig
+	for _, dir := range dirs {
+		dirGroups := byDir[dir]
+		sort.Slice(dirGroups, func(i, j int) bool {
+			return dirGroups[i].EmailId < dirGroups[j].EmailId
+		})
+		if err := writeYAMLFile(filepath.Join(outDir, dir, defaultGroupsFile), exportHeader, GroupsConfig{Groups: dirGroups}); err != nil {
+			return err
+		}
+
+		if dir == "" {
+			continue
+		}
+		r := Restriction{Path: filepath.ToSlash(dir) + "/*"}
+		for pattern := range patterns[dir] {
+			r.AllowedGroups = append(r.AllowedGroups, pattern)
+		}
+		sort.Strings(r.AllowedGroups)
+		restrictions.Restrictions = append(restrictions.Restrictions, r)
+	}
+
+	return writeYAMLFile(filepath.Join(outDir, defaultRestrictionsFile), exportHeader, restrictions)
+}
+
+// writeYAMLFile writes v to path as YAML indented by two spaces after the
+// given header, creating the parent directories as needed.
+func writeYAMLFile(path, header string, v interface{}) error {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("unable to generate yaml for %s: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("unable to generate yaml for %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0o644)
+}
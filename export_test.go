@@ -0,0 +1,100 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExportGroupsTree(t *testing.T) {
+	r, ac, gc := newFakeReconciler()
+	moderated := defaultSettings()
+	moderated.MessageModerationLevel = "MODERATE_ALL_MESSAGES"
+	addFakeGroup(ac, gc, "sig-foo-leads@example.com", moderated,
+		"b@example.com", MemberRole, "a@example.com", MemberRole, "owner@example.com", OwnerRole)
+	addFakeGroup(ac, gc, "sig-foo-chairs@example.com", defaultSettings(), "owner@example.com", OwnerRole)
+	addFakeGroup(ac, gc, "infra@example.com", defaultSettings(), "m@example.com", ManagerRole)
+
+	groups, err := r.liveGroups()
+	if err != nil {
+		t.Fatalf("unexpected error listing groups: %v", err)
+	}
+
+	outDir := t.TempDir()
+	if err := writeGroupsTree(outDir, groups, placeByPrefix("-")); err != nil {
+		t.Fatalf("unexpected error writing groups tree: %v", err)
+	}
+	if err := writeGroupsTree(outDir, groups, placeByPrefix("-")); err == nil {
+		t.Errorf("expected an error exporting to a non-empty directory")
+	}
+
+	var rc RestrictionsConfig
+	if err := rc.Load(filepath.Join(outDir, defaultRestrictionsFile)); err != nil {
+		t.Fatalf("unexpected error loading generated restrictions: %v", err)
+	}
+	if len(rc.Restrictions) != 1 || rc.Restrictions[0].Path != "sig/*" ||
+		!reflect.DeepEqual(rc.Restrictions[0].AllowedGroups, []string{"^sig-"}) {
+		t.Errorf("unexpected generated restrictions: %+v", rc.Restrictions)
+	}
+
+	var loaded GroupsConfig
+	if err := loaded.Load(outDir, GroupsLoadOptions{}, &rc); err != nil {
+		t.Fatalf("unexpected error loading exported groups: %v", err)
+	}
+	expected := []GoogleGroup{
+		{EmailId: "infra@example.com", Name: "infra", Managers: []string{"m@example.com"}, Path: "groups.yaml"},
+		{EmailId: "sig-foo-chairs@example.com", Name: "sig-foo-chairs", Owners: []string{"owner@example.com"}, Path: "sig/groups.yaml"},
+		{
+			EmailId:  "sig-foo-leads@example.com",
+			Name:     "sig-foo-leads",
+			Settings: map[string]string{"MessageModerationLevel": "MODERATE_ALL_MESSAGES"},
+			Owners:   []string{"owner@example.com"},
+			Members:  []string{"a@example.com", "b@example.com"},
+			Path:     "sig/groups.yaml",
+		},
+	}
+	if !reflect.DeepEqual(expected, loaded.Groups) {
+		t.Errorf("unexpected exported groups:\nexpected %+v\ngot      %+v", expected, loaded.Groups)
+	}
+}
+
+func TestPlaceByMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	content := "mappings:\n  - path: teams/infra\n    groups: ['^infra-', '^oncall@']\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var mc ExportMappingConfig
+	if err := mc.Load(path); err != nil {
+		t.Fatalf("unexpected error loading mapping: %v", err)
+	}
+
+	place := placeByMapping(&mc)
+	for email, expected := range map[string][2]string{
+		"infra-admins@example.com": {"teams/infra", "^infra-"},
+		"oncall@example.com":       {"teams/infra", "^oncall@"},
+		"leads@example.com":        {"", ""},
+	} {
+		dir, pattern := place(email)
+		if dir != expected[0] || pattern != expected[1] {
+			t.Errorf("unexpected placement for %s: expected %v, got [%s %s]", email, expected, dir, pattern)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+)
+
+// fakeAdminServiceClient is an in-memory AdminServiceClient. Every mutating
+// call is recorded in calls.
+type fakeAdminServiceClient struct {
+	groups  map[string]*admin.Group
+	members map[string][]*admin.Member
+	calls   []string
+}
+
+func newFakeAdminServiceClient() *fakeAdminServiceClient {
+	return &fakeAdminServiceClient{
+		groups:  map[string]*admin.Group{},
+		members: map[string][]*admin.Member{},
+	}
+}
+
+func notFound(key string) error {
+	return &googleapi.Error{Code: http.StatusNotFound, Message: fmt.Sprintf("%s not found", key)}
+}
+
+func (f *fakeAdminServiceClient) GetGroup(groupKey string) (*admin.Group, error) {
+	g, ok := f.groups[groupKey]
+	if !ok {
+		return nil, notFound(groupKey)
+	}
+	return g, nil
+}
+
+func (f *fakeAdminServiceClient) GetMember(groupKey, memberKey string) (*admin.Member, error) {
+	for _, m := range f.members[groupKey] {
+		if strings.EqualFold(m.Email, memberKey) || m.Id == memberKey {
+			return m, nil
+		}
+	}
+	return nil, notFound(memberKey)
+}
+
+func (f *fakeAdminServiceClient) ListGroups() (*admin.Groups, error) {
+	groups := &admin.Groups{}
+	for _, g := range f.groups {
+		groups.Groups = append(groups.Groups, g)
+	}
+	sort.Slice(groups.Groups, func(i, j int) bool {
+		return groups.Groups[i].Email < groups.Groups[j].Email
+	})
+	return groups, nil
+}
+
+func (f *fakeAdminServiceClient) ListMembers(groupKey string) (*admin.Members, error) {
+	if _, ok := f.groups[groupKey]; !ok {
+		return nil, notFound(groupKey)
+	}
+	members := &admin.Members{}
+	for _, m := range f.members[groupKey] {
+		copied := *m
+		members.Members = append(members.Members, &copied)
+	}
+	return members, nil
+}
+
+func (f *fakeAdminServiceClient) InsertGroup(group *admin.Group) (*admin.Group, error) {
+	f.calls = append(f.calls, "InsertGroup "+group.Email)
+	f.groups[group.Email] = group
+	return group, nil
+}
+
+func (f *fakeAdminServiceClient) InsertMember(groupKey string, member *admin.Member) (*admin.Member, error) {
+	f.calls = append(f.calls, fmt.Sprintf("InsertMember %s %s %s", groupKey, member.Email, member.Role))
+	// Like the API, emails are case-insensitive.
+	for _, m := range f.members[groupKey] {
+		if strings.EqualFold(m.Email, member.Email) {
+			return nil, &googleapi.Error{Code: http.StatusConflict, Message: "Member already exists."}
+		}
+	}
+	m := *member
+	m.Id = member.Email
+	f.members[groupKey] = append(f.members[groupKey], &m)
+	return &m, nil
+}
+
+func (f *fakeAdminServiceClient) UpdateGroup(groupKey string, group *admin.Group) (*admin.Group, error) {
+	f.calls = append(f.calls, "UpdateGroup "+groupKey)
+	f.groups[groupKey] = group
+	return group, nil
+}
+
+func (f *fakeAdminServiceClient) UpdateMember(groupKey, memberKey string, member *admin.Member) (*admin.Member, error) {
+	f.calls = append(f.calls, fmt.Sprintf("UpdateMember %s %s %s", groupKey, memberKey, member.Role))
+	for _, m := range f.members[groupKey] {
+		if strings.EqualFold(m.Email, memberKey) || m.Id == memberKey {
+			m.Role = member.Role
+			return m, nil
+		}
+	}
+	return nil, notFound(memberKey)
+}
+
+func (f *fakeAdminServiceClient) DeleteGroup(groupKey string) error {
+	f.calls = append(f.calls, "DeleteGroup "+groupKey)
+	delete(f.groups, groupKey)
+	delete(f.members, groupKey)
+	return nil
+}
+
+func (f *fakeAdminServiceClient) DeleteMember(groupKey, memberKey string) error {
+	f.calls = append(f.calls, fmt.Sprintf("DeleteMember %s %s", groupKey, memberKey))
+	members := f.members[groupKey]
+	for i, m := range members {
+		if strings.EqualFold(m.Email, memberKey) || m.Id == memberKey {
+			f.members[groupKey] = append(members[:i], members[i+1:]...)
+			return nil
+		}
+	}
+	return notFound(memberKey)
+}
+
+var _ AdminServiceClient = (*fakeAdminServiceClient)(nil)
+
+// fakeGroupServiceClient is an in-memory GroupServiceClient. Every mutating
+// call is recorded in calls.
+type fakeGroupServiceClient struct {
+	settings map[string]*groupssettings.Groups
+	calls    []string
+}
+
+func newFakeGroupServiceClient() *fakeGroupServiceClient {
+	return &fakeGroupServiceClient{settings: map[string]*groupssettings.Groups{}}
+}
+
+func (f *fakeGroupServiceClient) Get(groupUniqueID string) (*groupssettings.Groups, error) {
+	s, ok := f.settings[groupUniqueID]
+	if !ok {
+		return nil, notFound(groupUniqueID)
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (f *fakeGroupServiceClient) Patch(groupUniqueID string, groups *groupssettings.Groups) (*groupssettings.Groups, error) {
+	f.calls = append(f.calls, "Patch "+groupUniqueID)
+	copied := *groups
+	f.settings[groupUniqueID] = &copied
+	return groups, nil
+}
+
+var _ GroupServiceClient = (*fakeGroupServiceClient)(nil)
+
+// newFakeReconciler returns a Reconciler backed by in-memory clients.
+func newFakeReconciler() (*Reconciler, *fakeAdminServiceClient, *fakeGroupServiceClient) {
+	ac := newFakeAdminServiceClient()
+	gc := newFakeGroupServiceClient()
+	log := discardLogger()
+	r := &Reconciler{
+		adminService: &adminService{client: ac, log: log},
+		groupService: &groupService{client: gc, log: log},
+		log:          log,
+	}
+	return r, ac, gc
+}
+
+// defaultSettings returns group settings where every reconciled setting has
+// its default value.
+func defaultSettings() *groupssettings.Groups {
+	s := &groupssettings.Groups{AllowWebPosting: "true"}
+	for _, setting := range groupSettings {
+		if setting.Default != "" {
+			*setting.field(s) = setting.Default
+		}
+	}
+	return s
+}
+
+// addFakeGroup adds a group with the given settings and members, given as
+// alternating email and role, to the fake clients.
+func addFakeGroup(ac *fakeAdminServiceClient, gc *fakeGroupServiceClient, email string, settings *groupssettings.Groups, members ...string) {
+	ac.groups[email] = &admin.Group{Email: email, Name: email[:len(email)-len("@example.com")]}
+	gc.settings[email] = settings
+	for i := 0; i < len(members); i += 2 {
+		ac.members[email] = append(ac.members[email], &admin.Member{Email: members[i], Id: members[i], Role: members[i+1]})
+	}
+}
+
+// discardLogger returns an entry that logs nothing.
+func discardLogger() *logrus.Entry {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	return logrus.NewEntry(l)
+}
+
+// writeTree writes files, by path relative to root, under root.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
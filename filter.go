@@ -0,0 +1,131 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+// stringsFlag is a flag that can be given several times, collecting every
+// value.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// GroupFilter selects the groups of the config a run is limited to. A group
+// is selected if it matches one of the values of every non-empty field.
+type GroupFilter struct {
+	// Groups are the email-ids of the selected groups.
+	Groups []string
+	// Paths are patterns, as accepted by doublestar, matched against the
+	// path of the file each group is defined in, relative to the
+	// groups-path, such as "sig-foo/**".
+	Paths []string
+	// Labels are either "key=value", selecting the groups with that label,
+	// or "key", selecting the groups with the label whatever its value.
+	Labels []string
+}
+
+// IsEmpty returns true if f selects every group.
+func (f GroupFilter) IsEmpty() bool {
+	return len(f.Groups) == 0 && len(f.Paths) == 0 && len(f.Labels) == 0
+}
+
+// Validate returns an error if one of the patterns of f is invalid.
+func (f GroupFilter) Validate() error {
+	// doublestar only reports a malformed pattern when matching reaches it,
+	// so each segment is checked on its own.
+	for _, p := range f.Paths {
+		for _, segment := range strings.Split(p, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid path filter %q: %w", p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Matches returns true if f selects g.
+func (f GroupFilter) Matches(g GoogleGroup) bool {
+	return f.matchesGroup(g) && f.matchesPath(g) && f.matchesLabel(g)
+}
+
+func (f GroupFilter) matchesGroup(g GoogleGroup) bool {
+	if len(f.Groups) == 0 {
+		return true
+	}
+	for _, email := range f.Groups {
+		if strings.EqualFold(email, g.EmailId) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f GroupFilter) matchesPath(g GoogleGroup) bool {
+	if len(f.Paths) == 0 {
+		return true
+	}
+	for _, p := range f.Paths {
+		if ok, _ := doublestar.Match(p, g.Path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f GroupFilter) matchesLabel(g GoogleGroup) bool {
+	if len(f.Labels) == 0 {
+		return true
+	}
+	for _, l := range f.Labels {
+		key, value, hasValue := l, "", false
+		if i := strings.Index(l, "="); i >= 0 {
+			key, value, hasValue = l[:i], l[i+1:], true
+		}
+		v, ok := g.Labels[key]
+		if ok && (!hasValue || v == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the groups selected by f.
+func (f GroupFilter) Select(groups []GoogleGroup) []GoogleGroup {
+	if f.IsEmpty() {
+		return groups
+	}
+	var selected []GoogleGroup
+	for _, g := range groups {
+		if f.Matches(g) {
+			selected = append(selected, g)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupFilter(t *testing.T) {
+	groups := []GoogleGroup{
+		{EmailId: "sig-foo-leads@example.com", Path: "sig-foo/groups.yaml", Labels: map[string]string{"team": "foo"}},
+		{EmailId: "sig-foo-chairs@example.com", Path: "sig-foo/groups.yaml"},
+		{EmailId: "infra@example.com", Path: "groups.yaml", Labels: map[string]string{"team": "infra", "oncall": "yes"}},
+	}
+
+	cases := []struct {
+		name     string
+		filter   GroupFilter
+		expected []string
+	}{
+		{"empty", GroupFilter{}, []string{"sig-foo-leads@example.com", "sig-foo-chairs@example.com", "infra@example.com"}},
+		{"group", GroupFilter{Groups: []string{"Infra@example.com"}}, []string{"infra@example.com"}},
+		{"path", GroupFilter{Paths: []string{"sig-foo/**"}}, []string{"sig-foo-leads@example.com", "sig-foo-chairs@example.com"}},
+		{"label value", GroupFilter{Labels: []string{"team=foo"}}, []string{"sig-foo-leads@example.com"}},
+		{"label key", GroupFilter{Labels: []string{"oncall"}}, []string{"infra@example.com"}},
+		{"any value of a kind", GroupFilter{Labels: []string{"team=foo", "team=infra"}}, []string{"sig-foo-leads@example.com", "infra@example.com"}},
+		{"every kind", GroupFilter{Paths: []string{"sig-foo/**"}, Labels: []string{"team"}}, []string{"sig-foo-leads@example.com"}},
+	}
+	for _, c := range cases {
+		var selected []string
+		for _, g := range c.filter.Select(groups) {
+			selected = append(selected, g.EmailId)
+		}
+		if !reflect.DeepEqual(c.expected, selected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, selected)
+		}
+	}
+
+	if err := (GroupFilter{Paths: []string{"sig-foo/["}}).Validate(); err == nil {
+		t.Errorf("expected an error for an invalid path pattern")
+	}
+}
+
+func TestReconcileSelectedGroups(t *testing.T) {
+	groups := []GoogleGroup{
+		{EmailId: "selected@example.com", Labels: map[string]string{"team": "foo"}, Owners: []string{"owner@example.com"}},
+		{EmailId: "other@example.com", Owners: []string{"owner@example.com"}},
+	}
+	filter := GroupFilter{Labels: []string{"team=foo"}}
+
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+
+	for _, deleteGroups := range []bool{false, true} {
+		r, ac, gc := newFakeReconciler()
+		addFakeGroup(ac, gc, "selected@example.com", defaultSettings())
+		addFakeGroup(ac, gc, "other@example.com", defaultSettings())
+		addFakeGroup(ac, gc, "stale@example.com", defaultSettings())
+
+		if err := r.ReconcileSelectedGroups(groups, filter, deleteGroups); err != nil {
+			t.Fatalf("unexpected error reconciling groups: %v", err)
+		}
+
+		expected := []string{"InsertMember selected@example.com owner@example.com OWNER"}
+		if deleteGroups {
+			expected = append(expected, "DeleteGroup stale@example.com")
+		}
+		if !reflect.DeepEqual(expected, ac.calls) {
+			t.Errorf("deleteGroups %v: unexpected calls:\nexpected %v\ngot      %v", deleteGroups, expected, ac.calls)
+		}
+	}
+}
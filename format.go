@@ -0,0 +1,116 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// FormatGroupsFiles canonicalizes the YAML groups config files under rootDir
+// with GroupsFile.Format, rewriting them if write is true. It returns the
+// paths, relative to rootDir with forward slashes, of the files that were not
+// formatted.
+func FormatGroupsFiles(rootDir string, opts GroupsLoadOptions, write bool) ([]string, error) {
+	if len(opts.Patterns) == 0 {
+		opts.Patterns = []string{defaultGroupsFile}
+	}
+	ignore, err := loadIgnoreFile(filepath.Join(rootDir, defaultIgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var unformatted []string
+	paths, errs := findGroupsFiles(rootDir, opts, ignore)
+	for _, path := range paths {
+		if ext := strings.ToLower(filepath.Ext(path)); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error reading groups config file %s: %w", path, err))
+			continue
+		}
+		f, err := ParseGroupsFile(content)
+		if err == nil {
+			err = f.Format()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error formatting groups config at %s: %w", path, err))
+			continue
+		}
+		if bytes.Equal(content, f.Bytes()) {
+			continue
+		}
+		unformatted = append(unformatted, filepath.ToSlash(rel))
+		if !write {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := ioutil.WriteFile(path, f.Bytes(), info.Mode().Perm()); err != nil {
+			errs = append(errs, fmt.Errorf("error writing groups config file %s: %w", path, err))
+		}
+	}
+	return unformatted, utilerrors.NewAggregate(errs)
+}
+
+// formatCommand registers the flags of the fmt command, which canonicalizes
+// the YAML groups config files and prints the ones it rewrote.
+func formatCommand() func(configFilePath string) error {
+	check := flag.Bool("check", false, "only print the files that are not formatted, failing if there are any")
+
+	return func(configFilePath string) error {
+		if err := loadConfig(configFilePath, false); err != nil {
+			return err
+		}
+
+		var (
+			errs        []error
+			unformatted int
+		)
+		for _, t := range uniqueGroupsPathTenants() {
+			paths, err := FormatGroupsFiles(t.GroupsPath, config.GroupsLoadOptions(), !*check)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("tenant %s: %w", t.Name, err))
+			}
+			for _, path := range paths {
+				fmt.Println(filepath.Join(t.GroupsPath, filepath.FromSlash(path)))
+			}
+			unformatted += len(paths)
+		}
+		if *check && unformatted > 0 {
+			errs = append(errs, fmt.Errorf("%d groups config files are not formatted, run the fmt command", unformatted))
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// compileIgnoreMembers compiles ignore-members patterns. A pattern starting
+// with "@" matches every email in that domain; any other pattern is a
+// regular expression matching the whole email. Both ignore case.
+func compileIgnoreMembers(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		expr := p
+		if strings.HasPrefix(p, "@") {
+			expr = ".*" + regexp.QuoteMeta(p)
+		}
+		re, err := regexp.Compile("(?i)^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore-members pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// checkIgnoreMembers returns an error listing the invalid ignore-members
+// patterns of groups.
+func checkIgnoreMembers(groups []GoogleGroup) error {
+	var errs []error
+	for _, g := range groups {
+		if _, err := compileIgnoreMembers(g.IgnoreMembers); err != nil {
+			errs = append(errs, fmt.Errorf("group %q: %w", g.EmailId, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ignoredMembers returns a function reporting whether an email matches the
+// ignore-members patterns of the config or of g. Such members are neither
+// added, updated nor removed. Invalid patterns of g, which are rejected when
+// loading the config, match nothing.
+func ignoredMembers(g GoogleGroup) func(email string) bool {
+	res := config.IgnoreMembersRe
+	if groupRes, err := compileIgnoreMembers(g.IgnoreMembers); err == nil && len(groupRes) > 0 {
+		res = append(append([]*regexp.Regexp(nil), res...), groupRes...)
+	}
+	return func(email string) bool {
+		return matchesRegexList(email, res)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIgnoreMembers(t *testing.T) {
+	var err error
+	if config.IgnoreMembersRe, err = compileIgnoreMembers([]string{"@Robots.example.com"}); err != nil {
+		t.Fatalf("unexpected error compiling patterns: %v", err)
+	}
+	config.ConfirmChanges = true
+	defer func() {
+		config.ConfirmChanges = false
+		config.IgnoreMembersRe = nil
+	}()
+
+	g := GoogleGroup{
+		EmailId:          "group@example.com",
+		MembershipPolicy: AuthoritativePolicy,
+		IgnoreMembers:    []string{`bot-\d+@example\.com`},
+		Owners:           []string{"owner@example.com", "bot-1@example.com"},
+		Members:          []string{"member@example.com"},
+	}
+	ignored := ignoredMembers(g)
+	for email, expected := range map[string]bool{
+		"sa@robots.example.com":     true,
+		"sa@sub.robots.example.com": false,
+		"bot-2@example.com":         true,
+		"bot-2@example.com.evil":    false,
+		"member@example.com":        false,
+	} {
+		if ignored(email) != expected {
+			t.Errorf("expected ignored(%q) to be %v", email, expected)
+		}
+	}
+
+	r, ac, gc := newFakeReconciler()
+	addFakeGroup(ac, gc, g.EmailId, defaultSettings(),
+		"owner@example.com", OwnerRole, "bot-2@example.com", OwnerRole, "sa@robots.example.com", MemberRole, "stale@example.com", MemberRole)
+	if err := r.ReconcileGroups([]GoogleGroup{g}); err != nil {
+		t.Fatalf("unexpected error reconciling groups: %v", err)
+	}
+	expected := []string{
+		"InsertMember group@example.com member@example.com MEMBER",
+		"DeleteMember group@example.com stale@example.com",
+	}
+	if !reflect.DeepEqual(expected, ac.calls) {
+		t.Errorf("unexpected calls:\nexpected %v\ngot      %v", expected, ac.calls)
+	}
+
+	drifts, err := r.DetectDrift([]GoogleGroup{g})
+	if err != nil {
+		t.Fatalf("unexpected error detecting drift: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("expected no drift on ignored members, got %v", drifts)
+	}
+
+	if err := checkIgnoreMembers([]GoogleGroup{{EmailId: "group@example.com", IgnoreMembers: []string{"bot-(.*"}}}); err == nil {
+		t.Errorf("expected an error for an invalid pattern")
+	}
+}
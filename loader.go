@@ -0,0 +1,190 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+// GroupsLoadOptions controls which files GroupsConfig.Load reads and how it
+// walks the groups tree.
+type GroupsLoadOptions struct {
+	// Patterns is the list of file name patterns (as accepted by
+	// filepath.Match) identifying groups config files.
+	Patterns []string
+
+	// FollowSymlinks makes the loader follow symbolic links instead of
+	// rejecting them.
+	FollowSymlinks bool
+
+	// ManagedDomains, if not empty, is the list of domains groups may be
+	// defined in.
+	ManagedDomains []string
+}
+
+// GroupsLoadOptions returns the options used to load the groups tree
+// described by the Config.
+func (c *Config) GroupsLoadOptions() GroupsLoadOptions {
+	return GroupsLoadOptions{
+		Patterns:       c.GroupsFilePatterns,
+		FollowSymlinks: c.FollowSymlinks,
+	}
+}
+
+// loadIgnoreFile reads the patterns from an ignore file. Blank lines and lines
+// starting with "#" are skipped, as are trailing slashes. A missing ignore file
+// is not an error.
+func loadIgnoreFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading ignore file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.Trim(line, "/")
+		if _, err := doublestar.Match(line, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in ignore file %s: %w", line, path, err)
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading ignore file %s: %w", path, err)
+	}
+	return patterns, nil
+}
+
+// isIgnored reports whether relPath, a slash separated path relative to the
+// groups root, matches any of the ignore patterns. Patterns without a slash
+// match against the base name at any depth, like in .gitignore.
+func isIgnored(relPath string, patterns []string) bool {
+	base := filepath.Base(relPath)
+	for _, p := range patterns {
+		target := relPath
+		if !strings.Contains(p, "/") {
+			target = base
+		}
+		if match, err := doublestar.Match(p, target); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyPattern(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if match, err := filepath.Match(p, name); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// findGroupsFiles walks rootDir in lexical order and returns the paths of all
+// groups config files it contains, along with every error encountered on the
+// way. Paths are returned as seen from rootDir, even when reached through a
+// followed symlink, so restrictions apply to the logical location of a file.
+func findGroupsFiles(rootDir string, opts GroupsLoadOptions, ignore []string) ([]string, []error) {
+	var (
+		paths []string
+		errs  []error
+	)
+
+	// visited holds the resolved directories on the current walk stack, so
+	// that a symlink pointing back to one of its parents is reported instead
+	// of recursing forever.
+	visited := map[string]bool{}
+
+	var walk func(dir string)
+	walk = func(dir string) {
+		realDir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error resolving directory %s: %w", dir, err))
+			return
+		}
+		if visited[realDir] {
+			errs = append(errs, fmt.Errorf("symlink cycle detected at %s", dir))
+			return
+		}
+		visited[realDir] = true
+		defer delete(visited, realDir)
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error reading directory %s: %w", dir, err))
+			return
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			rel, err := filepath.Rel(rootDir, path)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if isIgnored(filepath.ToSlash(rel), ignore) {
+				continue
+			}
+
+			mode := entry.Type()
+			if mode&os.ModeSymlink != 0 {
+				info, err := os.Stat(path)
+				if !opts.FollowSymlinks {
+					// Only the symlinks that would be loaded if followed are
+					// worth failing on.
+					if (err == nil && info.IsDir()) || matchesAnyPattern(entry.Name(), opts.Patterns) {
+						errs = append(errs, fmt.Errorf("refusing to follow symlink %s (set follow-symlinks to allow it)", path))
+					} else {
+						logger.WithField("path", path).Debug("skipping symlink")
+					}
+					continue
+				}
+				if err != nil {
+					errs = append(errs, fmt.Errorf("error resolving symlink %s: %w", path, err))
+					continue
+				}
+				mode = info.Mode()
+			}
+
+			switch {
+			case mode.IsDir():
+				walk(path)
+			case mode.IsRegular() && matchesAnyPattern(entry.Name(), opts.Patterns):
+				paths = append(paths, path)
+			}
+		}
+	}
+	walk(rootDir)
+
+	return paths, errs
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// plannedRoles returns the role each member of the group g would have after
+// reconciling it with policy, given its live members, by lowercased email.
+// It follows AddOrUpdateGroupMembers, RemoveMembersFromGroup and
+// RemoveOwnerOrManagersFromGroup.
+func plannedRoles(g GoogleGroup, policy string, live []*admin.Member) map[string]string {
+	roles := make(map[string]string, len(live))
+	for _, m := range live {
+		roles[strings.ToLower(m.Email)] = m.Role
+	}
+	if policy == IgnorePolicy {
+		return roles
+	}
+
+	ignored := ignoredMembers(g)
+	configured := map[string]bool{}
+	for _, list := range []struct {
+		role    string
+		members []string
+	}{{OwnerRole, g.Owners}, {ManagerRole, g.Managers}, {MemberRole, g.Members}} {
+		for _, email := range list.members {
+			configured[strings.ToLower(email)] = true
+			if !ignored(email) {
+				roles[strings.ToLower(email)] = list.role
+			}
+		}
+	}
+
+	for _, m := range live {
+		email := strings.ToLower(m.Email)
+		if configured[email] || ignored(m.Email) {
+			continue
+		}
+		if policy == AuthoritativePolicy || policy == OwnersAuthoritativePolicy && m.Role != MemberRole {
+			delete(roles, email)
+		}
+	}
+	return roles
+}
+
+// checkLockout returns an error if reconciling the members of g with policy
+// would leave it without owners while it has some, or would remove the bot
+// or one of the break-glass accounts from it while it is protected. The
+// members of a group that does not exist yet are not checked.
+func (r *Reconciler) checkLockout(g GoogleGroup, policy string) error {
+	if policy == IgnorePolicy {
+		return nil
+	}
+	l, err := r.adminService.ListMembers(g.EmailId)
+	if err != nil {
+		if apierr, ok := err.(*googleapi.Error); ok && apierr.Code == http.StatusNotFound {
+			return nil
+		}
+		return fmt.Errorf("unable to retrieve members in group %q: %w", g.EmailId, err)
+	}
+
+	live := map[string]string{}
+	hadOwners := false
+	for _, m := range l.Members {
+		live[strings.ToLower(m.Email)] = m.Role
+		hadOwners = hadOwners || m.Role == OwnerRole
+	}
+	planned := plannedRoles(g, policy, l.Members)
+	hasOwners := false
+	for _, role := range planned {
+		hasOwners = hasOwners || role == OwnerRole
+	}
+	if hadOwners && !hasOwners {
+		return fmt.Errorf("refusing to reconcile the members of group %q as it would be left without owners", g.EmailId)
+	}
+
+	if !g.Protected {
+		return nil
+	}
+	for _, email := range append([]string{r.botID}, config.BreakGlass...) {
+		email = strings.ToLower(email)
+		if email == "" {
+			continue
+		}
+		if _, ok := planned[email]; !ok && live[email] != "" {
+			return fmt.Errorf("refusing to reconcile the members of protected group %q as it would remove %s", g.EmailId, email)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOwnerLockout(t *testing.T) {
+	testcases := []struct {
+		name          string
+		group         GoogleGroup
+		expectedError string
+	}{
+		{
+			name:  "owner replaced",
+			group: GoogleGroup{Owners: []string{"new-owner@example.com"}, Members: []string{"Bot@example.com", "glass@example.com"}},
+		},
+		{
+			name:          "every owner removed",
+			group:         GoogleGroup{Members: []string{"bot@example.com", "glass@example.com"}},
+			expectedError: "left without owners",
+		},
+		{
+			name:          "every owner demoted",
+			group:         GoogleGroup{Managers: []string{"owner@example.com"}, Members: []string{"bot@example.com", "glass@example.com"}},
+			expectedError: "left without owners",
+		},
+		{
+			name:  "every owner removed additively",
+			group: GoogleGroup{MembershipPolicy: AdditivePolicy},
+		},
+		{
+			name:  "bot removed from an unprotected group",
+			group: GoogleGroup{Owners: []string{"owner@example.com"}},
+		},
+		{
+			name:          "bot removed from a protected group",
+			group:         GoogleGroup{Protected: true, Owners: []string{"owner@example.com"}, Members: []string{"glass@example.com"}},
+			expectedError: "would remove bot@example.com",
+		},
+		{
+			name:          "break-glass account removed from a protected group",
+			group:         GoogleGroup{Protected: true, Owners: []string{"owner@example.com"}, Members: []string{"bot@example.com"}},
+			expectedError: "would remove glass@example.com",
+		},
+		{
+			name:  "break-glass account ignored in a protected group",
+			group: GoogleGroup{Protected: true, Owners: []string{"owner@example.com"}, Members: []string{"bot@example.com"}, IgnoreMembers: []string{"@example.com"}},
+		},
+	}
+
+	config.ConfirmChanges = true
+	config.BreakGlass = []string{"glass@example.com"}
+	defer func() {
+		config.ConfirmChanges = false
+		config.BreakGlass = nil
+	}()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := tc.group
+			g.EmailId = "group@example.com"
+			if g.MembershipPolicy == "" {
+				g.MembershipPolicy = AuthoritativePolicy
+			}
+
+			r, ac, gc := newFakeReconciler()
+			r.botID = "bot@example.com"
+			addFakeGroup(ac, gc, g.EmailId, defaultSettings(),
+				"owner@example.com", OwnerRole, "bot@example.com", MemberRole, "glass@example.com", MemberRole)
+			err := r.ReconcileGroups([]GoogleGroup{g})
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("expected an error containing %q, got %v", tc.expectedError, err)
+			}
+			if len(ac.calls) != 0 || len(gc.calls) != 0 {
+				t.Errorf("expected no change to be applied, got %v %v", ac.calls, gc.calls)
+			}
+		})
+	}
+}
+
+func TestLockoutEmailCase(t *testing.T) {
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+
+	r, ac, gc := newFakeReconciler()
+	g := GoogleGroup{EmailId: "group@example.com", MembershipPolicy: AuthoritativePolicy, Owners: []string{"Owner@example.com"}}
+	addFakeGroup(ac, gc, g.EmailId, defaultSettings(), "owner@example.com", OwnerRole, "other@example.com", MemberRole)
+	if err := r.ReconcileGroups([]GoogleGroup{g}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"DeleteMember group@example.com other@example.com"}
+	if !reflect.DeepEqual(expected, ac.calls) {
+		t.Errorf("expected calls %q, got %q", expected, ac.calls)
+	}
+	if l, _ := ac.ListMembers(g.EmailId); len(l.Members) != 1 || l.Members[0].Role != OwnerRole {
+		t.Errorf("expected the owner to be kept, got %+v", l.Members)
+	}
+}
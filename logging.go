@@ -0,0 +1,77 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Formats of the log output.
+const (
+	// TextLogFormat logs one line of key=value pairs per entry.
+	TextLogFormat = "text"
+	// JSONLogFormat logs one JSON object per entry.
+	JSONLogFormat = "json"
+)
+
+// Fields of the log entries about changes to the groups.
+const (
+	tenantField = "tenant"
+	groupField  = "group"
+	memberField = "member"
+	roleField   = "role"
+	actionField = "action"
+	dryRunField = "dryRun"
+)
+
+// logger is the logger of the commands. The Reconciler and the services it
+// uses are given an entry derived from it with the tenant they work on.
+var logger = logrus.New()
+
+// configureLogger sets the level and the format of l, writing to w.
+func configureLogger(l *logrus.Logger, w io.Writer, level, format string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+
+	switch format {
+	case TextLogFormat:
+		l.SetFormatter(&logrus.TextFormatter{DisableColors: true, FullTimestamp: true})
+	case JSONLogFormat:
+		l.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("invalid log format %q, must be one of %s, %s", format, TextLogFormat, JSONLogFormat)
+	}
+
+	l.SetLevel(lvl)
+	l.SetOutput(w)
+	return nil
+}
+
+// changeLog returns the entry logging a change of the given kind to group.
+// Changes that are not applied are marked as a dry-run.
+func changeLog(log *logrus.Entry, kind, group string) *logrus.Entry {
+	return log.WithFields(logrus.Fields{
+		actionField: kind,
+		groupField:  group,
+		dryRunField: !config.ConfirmChanges,
+	})
+}
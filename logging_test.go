@@ -0,0 +1,73 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestStructuredLogging(t *testing.T) {
+	var out bytes.Buffer
+	l := logrus.New()
+	if err := configureLogger(l, &out, "info", JSONLogFormat); err != nil {
+		t.Fatalf("unexpected error configuring logger: %v", err)
+	}
+
+	ac := newFakeAdminServiceClient()
+	addFakeGroup(ac, newFakeGroupServiceClient(), "team@example.com", defaultSettings())
+	as := &adminService{client: ac, log: l.WithField(tenantField, "default")}
+	if err := as.AddOrUpdateGroupMembers(GoogleGroup{EmailId: "team@example.com"}, MemberRole, []string{"new@example.com"}); err != nil {
+		t.Fatalf("unexpected error adding members: %v", err)
+	}
+
+	// The debug entry listing the members is filtered out by the level.
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single log entry, got:\n%s", out.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("expected a JSON log entry, got %q: %v", lines[0], err)
+	}
+	expected := map[string]interface{}{
+		tenantField: "default",
+		groupField:  "team@example.com",
+		memberField: "new@example.com",
+		roleField:   MemberRole,
+		actionField: AddMemberChange,
+		dryRunField: true,
+		"level":     "info",
+		"msg":       "would add member",
+	}
+	for k, v := range expected {
+		if entry[k] != v {
+			t.Errorf("unexpected %s in log entry: expected %v, got %v", k, v, entry[k])
+		}
+	}
+
+	if err := configureLogger(l, &out, "info", "xml"); err == nil {
+		t.Errorf("expected an error for an invalid log format")
+	}
+	if err := configureLogger(l, &out, "loud", TextLogFormat); err == nil {
+		t.Errorf("expected an error for an invalid log level")
+	}
+}
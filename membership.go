@@ -0,0 +1,99 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// The membership policies, which control how the owners, managers and members
+// of a group are reconciled.
+const (
+	// AuthoritativePolicy adds the owners, managers and members in the
+	// config and removes everyone else.
+	AuthoritativePolicy = "authoritative"
+	// OwnersAuthoritativePolicy adds the owners, managers and members in the
+	// config and removes the other owners and managers, leaving the other
+	// members alone. It is the default.
+	OwnersAuthoritativePolicy = "owners-authoritative"
+	// AdditivePolicy adds the owners, managers and members in the config and
+	// never removes anyone.
+	AdditivePolicy = "additive"
+	// IgnorePolicy leaves the owners, managers and members of the group
+	// alone.
+	IgnorePolicy = "ignore"
+)
+
+// reconcileMembersSetting is the deprecated setting that made the
+// membership of a group authoritative when set to "true", before
+// membership-policy existed.
+const reconcileMembersSetting = "ReconcileMembers"
+
+// validMembershipPolicy reports whether policy is one of the membership
+// policies.
+func validMembershipPolicy(policy string) bool {
+	switch policy {
+	case AuthoritativePolicy, OwnersAuthoritativePolicy, AdditivePolicy, IgnorePolicy:
+		return true
+	}
+	return false
+}
+
+// membershipPolicy returns the membership policy of g: its membership-policy
+// if set, otherwise the one implied by the deprecated ReconcileMembers
+// setting if set, otherwise the default of the config.
+func (g GoogleGroup) membershipPolicy() string {
+	if g.MembershipPolicy != "" {
+		return g.MembershipPolicy
+	}
+	if value, ok := g.Settings[reconcileMembersSetting]; ok {
+		if value == "true" {
+			return AuthoritativePolicy
+		}
+		return OwnersAuthoritativePolicy
+	}
+	if config.MembershipPolicy != "" {
+		return config.MembershipPolicy
+	}
+	return OwnersAuthoritativePolicy
+}
+
+// checkMembershipPolicies returns an error listing the groups with an
+// unknown membership-policy, and warns about the groups that still use the
+// deprecated ReconcileMembers setting.
+func checkMembershipPolicies(groups []GoogleGroup) error {
+	var errs []error
+	for _, g := range groups {
+		if g.MembershipPolicy != "" && !validMembershipPolicy(g.MembershipPolicy) {
+			errs = append(errs, fmt.Errorf("group %q has an unknown membership-policy %q", g.EmailId, g.MembershipPolicy))
+			continue
+		}
+		if _, ok := g.Settings[reconcileMembersSetting]; !ok {
+			continue
+		}
+		log := logger.WithFields(logrus.Fields{groupField: g.EmailId, "path": g.Path})
+		if g.MembershipPolicy != "" {
+			log.Warnf("the %s setting is deprecated and ignored as membership-policy is set, remove it", reconcileMembersSetting)
+			continue
+		}
+		log.Warnf("the %s setting is deprecated, replace it with membership-policy: %s", reconcileMembersSetting, g.membershipPolicy())
+	}
+	return utilerrors.NewAggregate(errs)
+}
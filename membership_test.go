@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMembershipPolicy(t *testing.T) {
+	const (
+		add          = "InsertMember group@example.com new@example.com MEMBER"
+		removeOwner  = "DeleteMember group@example.com stale-owner@example.com"
+		removeMember = "DeleteMember group@example.com stale-member@example.com"
+	)
+	testcases := []struct {
+		name           string
+		group          GoogleGroup
+		defaultPolicy  string
+		expectedPolicy string
+		expectedCalls  []string
+	}{
+		{
+			name:           "default",
+			expectedPolicy: OwnersAuthoritativePolicy,
+			expectedCalls:  []string{add, removeOwner},
+		},
+		{
+			name:           "default from the config",
+			defaultPolicy:  AdditivePolicy,
+			expectedPolicy: AdditivePolicy,
+			expectedCalls:  []string{add},
+		},
+		{
+			name:           "authoritative",
+			group:          GoogleGroup{MembershipPolicy: AuthoritativePolicy},
+			defaultPolicy:  AdditivePolicy,
+			expectedPolicy: AuthoritativePolicy,
+			expectedCalls:  []string{add, removeOwner, removeMember},
+		},
+		{
+			name:           "ignore",
+			group:          GoogleGroup{MembershipPolicy: IgnorePolicy},
+			expectedPolicy: IgnorePolicy,
+		},
+		{
+			name:           "deprecated ReconcileMembers setting",
+			group:          GoogleGroup{Settings: map[string]string{"ReconcileMembers": "true"}},
+			defaultPolicy:  AdditivePolicy,
+			expectedPolicy: AuthoritativePolicy,
+			expectedCalls:  []string{add, removeOwner, removeMember},
+		},
+		{
+			name:           "deprecated ReconcileMembers setting overridden",
+			group:          GoogleGroup{MembershipPolicy: AdditivePolicy, Settings: map[string]string{"ReconcileMembers": "true"}},
+			expectedPolicy: AdditivePolicy,
+			expectedCalls:  []string{add},
+		},
+	}
+
+	config.ConfirmChanges = true
+	defer func() {
+		config.ConfirmChanges = false
+		config.MembershipPolicy = ""
+	}()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			config.MembershipPolicy = tc.defaultPolicy
+			g := tc.group
+			g.EmailId = "group@example.com"
+			g.Owners = []string{"owner@example.com"}
+			g.Members = []string{"new@example.com"}
+			if policy := g.membershipPolicy(); policy != tc.expectedPolicy {
+				t.Errorf("unexpected policy: expected %q, got %q", tc.expectedPolicy, policy)
+			}
+
+			r, ac, gc := newFakeReconciler()
+			addFakeGroup(ac, gc, g.EmailId, defaultSettings(),
+				"owner@example.com", OwnerRole, "stale-owner@example.com", OwnerRole, "stale-member@example.com", MemberRole)
+			if err := r.ReconcileSelectedGroups([]GoogleGroup{g}, GroupFilter{}, false); err != nil {
+				t.Fatalf("unexpected error reconciling groups: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expectedCalls, ac.calls) {
+				t.Errorf("unexpected calls:\nexpected %v\ngot      %v", tc.expectedCalls, ac.calls)
+			}
+		})
+	}
+
+	if err := checkMembershipPolicies([]GoogleGroup{{EmailId: "group@example.com", MembershipPolicy: "strict"}}); err == nil {
+		t.Errorf("expected an error for an unknown membership-policy")
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+)
+
+// Kinds of changes made to the groups, as counted by changesTotal.
+const (
+	CreateGroupChange = "create_group"
+	UpdateGroupChange = "update_group"
+	DeleteGroupChange = "delete_group"
+	// SoftDeleteGroupChange marks a group as pending deletion.
+	SoftDeleteGroupChange = "soft_delete_group"
+	// RestoreGroupChange undoes SoftDeleteGroupChange.
+	RestoreGroupChange   = "restore_group"
+	UpdateSettingsChange = "update_settings"
+	AddMemberChange      = "add_member"
+	UpdateMemberChange   = "update_member"
+	RemoveMemberChange   = "remove_member"
+)
+
+var (
+	// metricsRegistry holds every metric of the tool. It is served on
+	// /metrics by the serve command, and written to a file in the
+	// Prometheus text format by the other commands if requested.
+	metricsRegistry = prometheus.NewRegistry()
+
+	changesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ggreconcile_changes_total",
+		Help: "Changes to the groups, by kind, planned in dry-run mode or applied otherwise.",
+	}, []string{"tenant", "kind", "phase"})
+
+	apiRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ggreconcile_api_requests_total",
+		Help: "Calls to the Google APIs by method and HTTP status code, or \"error\" if no response was received.",
+	}, []string{"method", "code"})
+
+	runDurationSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ggreconcile_run_duration_seconds",
+		Help: "Duration of the last run for a tenant.",
+	}, []string{"tenant", "mode"})
+
+	lastSuccessTimestampSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ggreconcile_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful run for a tenant.",
+	}, []string{"tenant", "mode"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(changesTotal, apiRequestsTotal, runDurationSeconds, lastSuccessTimestampSeconds)
+}
+
+// recordChange counts a change of the given kind to the groups of tenant,
+// as applied if applied is true or as planned otherwise.
+func recordChange(tenant, kind string, applied bool) {
+	phase := "planned"
+	if applied {
+		phase = "applied"
+	}
+	changesTotal.WithLabelValues(tenant, kind, phase).Inc()
+}
+
+// recordRun records the duration of a run for tenant that started at start,
+// and the time it ended if it succeeded.
+func recordRun(tenant, mode string, start time.Time, err error) {
+	now := time.Now()
+	runDurationSeconds.WithLabelValues(tenant, mode).Set(now.Sub(start).Seconds())
+	if err == nil {
+		lastSuccessTimestampSeconds.WithLabelValues(tenant, mode).Set(float64(now.Unix()))
+	}
+}
+
+// recordAPIRequest counts a call to method that returned err.
+func recordAPIRequest(method string, err error) {
+	code := strconv.Itoa(200)
+	if err != nil {
+		var apierr *googleapi.Error
+		if errors.As(err, &apierr) {
+			code = strconv.Itoa(apierr.Code)
+		} else {
+			code = "error"
+		}
+	}
+	apiRequestsTotal.WithLabelValues(method, code).Inc()
+}
+
+// instrumentedAdminServiceClient is an AdminServiceClient counting the calls
+// made to client.
+type instrumentedAdminServiceClient struct {
+	client AdminServiceClient
+}
+
+func (c *instrumentedAdminServiceClient) GetGroup(groupKey string) (*admin.Group, error) {
+	g, err := c.client.GetGroup(groupKey)
+	recordAPIRequest("admin.groups.get", err)
+	return g, err
+}
+
+func (c *instrumentedAdminServiceClient) GetMember(groupKey, memberKey string) (*admin.Member, error) {
+	m, err := c.client.GetMember(groupKey, memberKey)
+	recordAPIRequest("admin.members.get", err)
+	return m, err
+}
+
+func (c *instrumentedAdminServiceClient) ListGroups() (*admin.Groups, error) {
+	g, err := c.client.ListGroups()
+	recordAPIRequest("admin.groups.list", err)
+	return g, err
+}
+
+func (c *instrumentedAdminServiceClient) ListMembers(groupKey string) (*admin.Members, error) {
+	m, err := c.client.ListMembers(groupKey)
+	recordAPIRequest("admin.members.list", err)
+	return m, err
+}
+
+func (c *instrumentedAdminServiceClient) InsertGroup(group *admin.Group) (*admin.Group, error) {
+	g, err := c.client.InsertGroup(group)
+	recordAPIRequest("admin.groups.insert", err)
+	return g, err
+}
+
+func (c *instrumentedAdminServiceClient) InsertMember(groupKey string, member *admin.Member) (*admin.Member, error) {
+	m, err := c.client.InsertMember(groupKey, member)
+	recordAPIRequest("admin.members.insert", err)
+	return m, err
+}
+
+func (c *instrumentedAdminServiceClient) UpdateGroup(groupKey string, group *admin.Group) (*admin.Group, error) {
+	g, err := c.client.UpdateGroup(groupKey, group)
+	recordAPIRequest("admin.groups.update", err)
+	return g, err
+}
+
+func (c *instrumentedAdminServiceClient) UpdateMember(groupKey, memberKey string, member *admin.Member) (*admin.Member, error) {
+	m, err := c.client.UpdateMember(groupKey, memberKey, member)
+	recordAPIRequest("admin.members.update", err)
+	return m, err
+}
+
+func (c *instrumentedAdminServiceClient) DeleteGroup(groupKey string) error {
+	err := c.client.DeleteGroup(groupKey)
+	recordAPIRequest("admin.groups.delete", err)
+	return err
+}
+
+func (c *instrumentedAdminServiceClient) DeleteMember(groupKey, memberKey string) error {
+	err := c.client.DeleteMember(groupKey, memberKey)
+	recordAPIRequest("admin.members.delete", err)
+	return err
+}
+
+var _ AdminServiceClient = (*instrumentedAdminServiceClient)(nil)
+
+// instrumentedGroupServiceClient is a GroupServiceClient counting the calls
+// made to client.
+type instrumentedGroupServiceClient struct {
+	client GroupServiceClient
+}
+
+func (c *instrumentedGroupServiceClient) Get(groupUniqueID string) (*groupssettings.Groups, error) {
+	g, err := c.client.Get(groupUniqueID)
+	recordAPIRequest("groupssettings.groups.get", err)
+	return g, err
+}
+
+func (c *instrumentedGroupServiceClient) Patch(groupUniqueID string, groups *groupssettings.Groups) (*groupssettings.Groups, error) {
+	g, err := c.client.Patch(groupUniqueID, groups)
+	recordAPIRequest("groupssettings.groups.patch", err)
+	return g, err
+}
+
+var _ GroupServiceClient = (*instrumentedGroupServiceClient)(nil)
@@ -0,0 +1,131 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+func TestMetrics(t *testing.T) {
+	ac := newFakeAdminServiceClient()
+	gc := newFakeGroupServiceClient()
+	log := discardLogger()
+	r := &Reconciler{
+		adminService: &adminService{client: &instrumentedAdminServiceClient{client: ac}, log: log, tenant: "metrics"},
+		groupService: &groupService{client: &instrumentedGroupServiceClient{client: gc}, log: log, tenant: "metrics"},
+		log:          log,
+	}
+	addFakeGroup(ac, gc, "team@example.com", defaultSettings(), "owner@example.com", OwnerRole)
+	addFakeGroup(ac, gc, "stale@example.com", defaultSettings())
+
+	groups := []GoogleGroup{
+		{EmailId: "team@example.com", Owners: []string{"owner@example.com"}, Members: []string{"new@example.com"}},
+		{EmailId: "missing@example.com"},
+	}
+	if err := r.ReconcileGroups(groups); err != nil {
+		t.Fatalf("unexpected error reconciling groups: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "ggreconcile.prom")
+	if err := prometheus.WriteToTextfile(path, metricsRegistry); err != nil {
+		t.Fatalf("unexpected error writing metrics: %v", err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading metrics: %v", err)
+	}
+
+	expected := []string{
+		`ggreconcile_changes_total{kind="add_member",phase="planned",tenant="metrics"} 1`,
+		`ggreconcile_changes_total{kind="create_group",phase="planned",tenant="metrics"} 1`,
+		`ggreconcile_changes_total{kind="delete_group",phase="planned",tenant="metrics"} 1`,
+		`ggreconcile_api_requests_total{code="404",method="admin.groups.get"}`,
+		`ggreconcile_api_requests_total{code="200",method="admin.groups.list"}`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(string(content), line) {
+			t.Errorf("expected metrics to contain %s, got:\n%s", line, content)
+		}
+	}
+	if len(ac.calls) > 0 || len(gc.calls) > 0 {
+		t.Errorf("expected no changes in dry-run mode, got %v %v", ac.calls, gc.calls)
+	}
+
+	w := httptest.NewRecorder()
+	(&server{}).handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), expected[0]) {
+		t.Errorf("expected /metrics to serve the metrics, got %d:\n%s", w.Code, w.Body)
+	}
+}
+
+// failingInsertMemberClient is a fakeAdminServiceClient failing to add
+// members.
+type failingInsertMemberClient struct {
+	*fakeAdminServiceClient
+}
+
+func (f failingInsertMemberClient) InsertMember(string, *admin.Member) (*admin.Member, error) {
+	return nil, errors.New("backend error")
+}
+
+func TestMetricsAppliedChanges(t *testing.T) {
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+
+	ac := newFakeAdminServiceClient()
+	gc := newFakeGroupServiceClient()
+	log := discardLogger()
+	r := &Reconciler{
+		adminService: &adminService{client: failingInsertMemberClient{ac}, log: log, tenant: "metrics-applied"},
+		groupService: &groupService{client: gc, log: log, tenant: "metrics-applied"},
+		log:          log,
+	}
+	addFakeGroup(ac, gc, "team@example.com", defaultSettings(), "owner@example.com", OwnerRole)
+	gc.settings["missing@example.com"] = defaultSettings()
+
+	groups := []GoogleGroup{
+		{EmailId: "team@example.com", Owners: []string{"owner@example.com"}, Members: []string{"new@example.com"}},
+		{EmailId: "missing@example.com"},
+	}
+	if err := r.ReconcileGroups(groups); err == nil {
+		t.Fatal("expected an error adding a member")
+	}
+
+	path := filepath.Join(t.TempDir(), "ggreconcile.prom")
+	if err := prometheus.WriteToTextfile(path, metricsRegistry); err != nil {
+		t.Fatalf("unexpected error writing metrics: %v", err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading metrics: %v", err)
+	}
+	if line := `ggreconcile_changes_total{kind="create_group",phase="applied",tenant="metrics-applied"} 1`; !strings.Contains(string(content), line) {
+		t.Errorf("expected metrics to contain %s, got:\n%s", line, content)
+	}
+	if strings.Contains(string(content), `kind="add_member",phase="applied",tenant="metrics-applied"`) {
+		t.Errorf("expected the failed change not to be counted as applied, got:\n%s", content)
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// OffboardRemoval is an entry removed from a groups config file.
+type OffboardRemoval struct {
+	Group string
+	Role  string
+	// Path is the path of the file, relative to the groups-path, with
+	// forward slashes.
+	Path string
+}
+
+// OffboardResult is what offboarding an email changed in a groups tree.
+type OffboardResult struct {
+	Email    string
+	Removals []OffboardRemoval
+	// Ownerless are the groups that had owners and are left without any.
+	Ownerless []string
+	// Unedited are the groups config files that are not in YAML and still
+	// list the email, to be edited by hand.
+	Unedited []string
+}
+
+// Offboard removes email from every groups config file under rootDir,
+// rewriting the YAML files unless dryRun is true. Files in other formats
+// that list the email are reported as unedited.
+func Offboard(rootDir string, opts GroupsLoadOptions, email string, dryRun bool) (*OffboardResult, error) {
+	if len(opts.Patterns) == 0 {
+		opts.Patterns = []string{defaultGroupsFile}
+	}
+	ignore, err := loadIgnoreFile(filepath.Join(rootDir, defaultIgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OffboardResult{Email: email}
+	paths, errs := findGroupsFiles(rootDir, opts, ignore)
+	for _, path := range paths {
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error reading groups config file %s: %w", path, err))
+			continue
+		}
+
+		var gc GroupsConfig
+		if err := unmarshalGroupsConfig(path, content, &gc); err != nil {
+			errs = append(errs, fmt.Errorf("error parsing groups config at %s: %w", path, err))
+			continue
+		}
+		var listed []string
+		for _, g := range gc.Groups {
+			if groupRole(g, email) != "" {
+				listed = append(listed, g.EmailId)
+			}
+		}
+		if len(listed) == 0 {
+			continue
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); ext != ".yaml" && ext != ".yml" {
+			result.Unedited = append(result.Unedited, rel)
+			continue
+		}
+
+		removals, ownerless, edited, err := offboardFile(content, listed, email)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error editing groups config at %s: %w", path, err))
+			continue
+		}
+		for i := range removals {
+			removals[i].Path = rel
+		}
+		result.Removals = append(result.Removals, removals...)
+		result.Ownerless = append(result.Ownerless, ownerless...)
+		if dryRun {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := ioutil.WriteFile(path, edited, info.Mode().Perm()); err != nil {
+			errs = append(errs, fmt.Errorf("error writing groups config file %s: %w", path, err))
+		}
+	}
+	return result, utilerrors.NewAggregate(errs)
+}
+
+// offboardFile removes email from groups, the groups of content, a YAML
+// groups config file, that list it. It returns the removals, whose Path is
+// left empty, the groups left without owners and the new content.
+func offboardFile(content []byte, groups []string, email string) ([]OffboardRemoval, []string, []byte, error) {
+	f, err := ParseGroupsFile(content)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var (
+		removals  []OffboardRemoval
+		ownerless []string
+	)
+	for _, group := range groups {
+		owners, err := f.Members(group, OwnerRole)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		roles, err := f.RemoveMember(group, email)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, role := range roles {
+			removals = append(removals, OffboardRemoval{Group: group, Role: role})
+		}
+		if left, _ := f.Members(group, OwnerRole); len(owners) > 0 && len(left) == 0 {
+			ownerless = append(ownerless, group)
+		}
+	}
+	return removals, ownerless, f.Bytes(), nil
+}
+
+// Summary describes the result in Markdown, for the description of the pull
+// request offboarding the email.
+func (r *OffboardResult) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Offboard %s\n\n", r.Email)
+	if len(r.Removals) == 0 {
+		fmt.Fprintf(&b, "%s is not in any group of the config.\n", r.Email)
+	} else {
+		groups := map[string]bool{}
+		for _, rm := range r.Removals {
+			groups[rm.Group] = true
+		}
+		fmt.Fprintf(&b, "Remove %s from %d groups:\n\n", r.Email, len(groups))
+		for _, rm := range r.Removals {
+			fmt.Fprintf(&b, "- `%s` (%s) in `%s`\n", rm.Group, rm.Role, rm.Path)
+		}
+	}
+	if len(r.Ownerless) > 0 {
+		b.WriteString("\n**Warning:** these groups are left without owners and need a new one before this can be applied:\n\n")
+		for _, g := range r.Ownerless {
+			fmt.Fprintf(&b, "- `%s`\n", g)
+		}
+	}
+	if len(r.Unedited) > 0 {
+		b.WriteString("\nThese files still list the email and must be edited by hand:\n\n")
+		for _, path := range r.Unedited {
+			fmt.Fprintf(&b, "- `%s`\n", path)
+		}
+	}
+	return b.String()
+}
+
+// offboardCommand registers the flags of the offboard command, which removes
+// an email from every groups config file and prints a summary of the changes.
+func offboardCommand() func(configFilePath string) error {
+	dryRun := flag.Bool("dry-run", false, "only print the summary, without rewriting the groups config files")
+
+	return func(configFilePath string) error {
+		if flag.NArg() != 1 {
+			return fmt.Errorf("offboard: expected a single email, got %v", flag.Args())
+		}
+		email := flag.Arg(0)
+		if err := loadConfig(configFilePath, false); err != nil {
+			return err
+		}
+
+		var errs []error
+		for _, t := range uniqueGroupsPathTenants() {
+			result, err := Offboard(t.GroupsPath, config.GroupsLoadOptions(), email, *dryRun)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("tenant %s: %w", t.Name, err))
+			}
+			if result == nil {
+				continue
+			}
+			log := logger.WithField(tenantField, t.Name)
+			for _, g := range result.Ownerless {
+				log.WithField(groupField, g).Warn("offboarding leaves the group without owners")
+			}
+			for _, path := range result.Unedited {
+				log.WithField("path", path).Warn("the groups config file is not in YAML and must be edited by hand")
+			}
+			if len(config.Tenants) > 1 {
+				fmt.Printf("<!-- tenant: %s -->\n", t.Name)
+			}
+			fmt.Print(result.Summary())
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+}
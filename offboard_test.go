@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOffboard(t *testing.T) {
+	rootDir := t.TempDir()
+	files := map[string]string{
+		"groups.yaml": `# Groups of the foo SIG.
+groups:
+  # The team.
+  - email-id: team@example.com
+    name: team
+    owners:
+      - alice@example.com # lead
+      - bob@example.com
+    members:
+      # On call until the end of the year.
+      - email: Alice@example.com
+        expires: 2026-12-31
+
+  - email-id: leads@example.com
+    owners: [alice@example.com]
+    managers: ["carol@example.com", alice@example.com, 'dave@example.com']
+    settings:
+      WhoCanPostMessage: "ANYONE_CAN_POST"
+`,
+		"sig-bar/groups.yaml": "groups:\n  - email-id: bar@example.com\n    owners:\n      - bob@example.com\n",
+		"sig-baz/groups.json": `{"groups": [{"email-id": "baz@example.com", "members": ["alice@example.com"]}]}`,
+	}
+	writeTree(t, rootDir, files)
+
+	opts := GroupsLoadOptions{Patterns: []string{"groups.yaml", "groups.json"}}
+	result, err := Offboard(rootDir, opts, "alice@example.com", false)
+	if err != nil {
+		t.Fatalf("unexpected error offboarding: %v", err)
+	}
+	expected := &OffboardResult{
+		Email: "alice@example.com",
+		Removals: []OffboardRemoval{
+			{Group: "team@example.com", Role: OwnerRole, Path: "groups.yaml"},
+			{Group: "team@example.com", Role: MemberRole, Path: "groups.yaml"},
+			{Group: "leads@example.com", Role: OwnerRole, Path: "groups.yaml"},
+			{Group: "leads@example.com", Role: ManagerRole, Path: "groups.yaml"},
+		},
+		Ownerless: []string{"leads@example.com"},
+		Unedited:  []string{"sig-baz/groups.json"},
+	}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("unexpected result:\nexpected %+v\ngot      %+v", expected, result)
+	}
+
+	expectedContent := `# Groups of the foo SIG.
+groups:
+  # The team.
+  - email-id: team@example.com
+    name: team
+    owners:
+      - bob@example.com
+
+  - email-id: leads@example.com
+    managers: ["carol@example.com", 'dave@example.com']
+    settings:
+      WhoCanPostMessage: "ANYONE_CAN_POST"
+`
+	for name, content := range map[string]string{
+		"groups.yaml":         expectedContent,
+		"sig-bar/groups.yaml": files["sig-bar/groups.yaml"],
+	} {
+		got, err := ioutil.ReadFile(filepath.Join(rootDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("unexpected content of %s:\nexpected:\n%s\ngot:\n%s", name, content, got)
+		}
+	}
+
+	summary := result.Summary()
+	for _, s := range []string{
+		"Remove alice@example.com from 2 groups:",
+		"- `leads@example.com` (MANAGER) in `groups.yaml`",
+		"**Warning:** these groups are left without owners",
+		"- `sig-baz/groups.json`",
+	} {
+		if !strings.Contains(summary, s) {
+			t.Errorf("expected the summary to contain %q, got:\n%s", s, summary)
+		}
+	}
+}
@@ -17,23 +17,25 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
-	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"github.com/BurntSushi/toml"
 	"github.com/bmatcuk/doublestar"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
-	"golang.org/x/oauth2/google"
-	admin "google.golang.org/api/admin/directory/v1"
-	"google.golang.org/api/groupssettings/v1"
+	groupssettings "google.golang.org/api/groupssettings/v1"
 	"google.golang.org/api/option"
-	secretmanagerpb "google.golang.org/genproto/googleapis/cloud/secretmanager/v1"
 	"gopkg.in/yaml.v3"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -41,12 +43,103 @@ import (
 )
 
 type Config struct {
+	// Tenant describes the Google Workspace org to reconcile when Tenants is
+	// empty. Otherwise, it holds the defaults for the entries of Tenants.
+	Tenant `yaml:",inline"`
+
+	// Tenants is the list of Google Workspace orgs reconciled in one run.
+	// Fields left empty in an entry are inherited from the top level.
+	Tenants []Tenant `yaml:"tenants,omitempty"`
+
+	// GroupsFilePatterns is the list of file name patterns (as accepted by
+	// filepath.Match) used to find groups config files under GroupsPath.
+	// The format of each file is picked from its extension: .yaml/.yml,
+	// .json or .toml. If not specified, it defaults to "groups.yaml".
+	GroupsFilePatterns []string `yaml:"groups-file-patterns,omitempty"`
+
+	// FollowSymlinks controls how symbolic links found under GroupsPath are
+	// handled. If true, they are followed; otherwise loading fails when one
+	// is found outside of an ignored subtree.
+	FollowSymlinks bool `yaml:"follow-symlinks,omitempty"`
+
+	// AuditLogPath is the path to the file every applied change is appended
+	// to, one JSON object per line. Relative paths are relative to the
+	// directory containing the config.yaml file. If not specified, applied
+	// changes are only logged.
+	AuditLogPath string `yaml:"audit-log,omitempty"`
+
+	// SnapshotDir is the directory the live state of the groups is written
+	// to before changes are applied, to be restored with the rollback
+	// command. Relative paths are relative to the directory containing the
+	// config.yaml file. If not specified, it defaults to a directory in the
+	// user cache directory.
+	SnapshotDir string `yaml:"snapshot-dir,omitempty"`
+
+	// SoftDelete, if set, makes groups removed from the config pending
+	// deletion for a while before they are deleted.
+	SoftDelete *SoftDeleteConfig `yaml:"soft-delete,omitempty"`
+
+	// ExpiryWarningDays is how many days before they expire memberships are
+	// warned about. If not specified, it defaults to 14; a negative value
+	// disables the warnings.
+	ExpiryWarningDays int `yaml:"expiry-warning-days,omitempty"`
+
+	// MembershipPolicy is the membership-policy of the groups that don't set
+	// one. If not specified, it defaults to owners-authoritative.
+	MembershipPolicy string `yaml:"membership-policy,omitempty"`
+
+	// IgnoreMembers is the list of patterns of the emails the reconciler
+	// never adds, updates or removes in any group, such as service accounts
+	// or Google-managed addresses added out of band. A pattern starting with
+	// "@" matches a domain; any other pattern is a regular expression
+	// matching the whole email.
+	//
+	// Compiles to IgnoreMembersRe during config load.
+	IgnoreMembers   []string         `yaml:"ignore-members,omitempty"`
+	IgnoreMembersRe []*regexp.Regexp `yaml:"-"`
+
+	// BreakGlass is the list of emails of the accounts that must never be
+	// removed from protected groups, along with the bot-id of each tenant.
+	BreakGlass []string `yaml:"break-glass,omitempty"`
+
+	// If false, don't make any mutating API calls
+	ConfirmChanges bool
+}
+
+// Tenant describes a Google Workspace org whose groups are reconciled.
+type Tenant struct {
+	// Name identifies the tenant in logs and summaries.
+	// If not specified for the top level tenant, it defaults to "default".
+	Name string `yaml:"name,omitempty"`
+
+	// CustomerID is the ID of the Google Workspace customer whose groups are
+	// listed. If neither CustomerID nor Domain is specified, it defaults to
+	// "my_customer", the customer the BotID belongs to.
+	CustomerID string `yaml:"customer-id,omitempty"`
+
+	// Domain restricts the listed groups to the ones in this domain.
+	Domain string `yaml:"domain,omitempty"`
+
+	// ManagedDomains is the list of domains whose groups are managed by this
+	// repository. When set, groups are listed per domain, only groups in these
+	// domains are created or deleted, and groups config files defining a group
+	// outside of them are rejected. If not specified, it defaults to Domain.
+	ManagedDomains []string `yaml:"managed-domains,omitempty"`
+
 	// the email id for the bot/service account
 	BotID string `yaml:"bot-id"`
 
 	// the gcloud secret containing a service account key to authenticate with
 	SecretVersion string `yaml:"secret-version,omitempty"`
 
+	// Credentials selects where the credentials used to authenticate with
+	// come from. If not specified, the key in SecretVersion is used.
+	Credentials CredentialsConfig `yaml:"credentials,omitempty"`
+
+	// APIEndpoint is the root URL the Google APIs are called at instead of
+	// https://www.googleapis.com/, such as the one of a local stand-in.
+	APIEndpoint string `yaml:"api-endpoint,omitempty"`
+
 	// GroupsPath is the path to the directory with
 	// groups.yaml files containing groups/members information.
 	// It must be an absolute path. If not specified,
@@ -57,32 +150,66 @@ type Config struct {
 	// containing restrictions for which groups can be defined in sub-directories.
 	// If not specified, it defaults to "restrictions.yaml" in the groups-path directory.
 	RestrictionsPath string `yaml:"restrictions-path,omitempty"`
-
-	// If false, don't make any mutating API calls
-	ConfirmChanges bool
 }
 
 type GroupsConfig struct {
 	// This file has the list of groups in inclusivenaming.org gsuite org that we use
 	// for granting permissions to various community resources.
-	Groups []GoogleGroup `yaml:"groups,omitempty" json:"groups,omitempty"`
+	Groups []GoogleGroup `yaml:"groups,omitempty" json:"groups,omitempty" toml:"groups,omitempty"`
 }
 
 type GoogleGroup struct {
-	EmailId     string `yaml:"email-id" json:"email-id"`
-	Name        string `yaml:"name" json:"name"`
-	Description string `yaml:"description" json:"description"`
+	EmailId     string `yaml:"email-id" json:"email-id" toml:"email-id"`
+	Name        string `yaml:"name" json:"name" toml:"name"`
+	Description string `yaml:"description" json:"description" toml:"description"`
+
+	Settings map[string]string `yaml:"settings,omitempty" json:"settings,omitempty" toml:"settings,omitempty"`
+
+	// +optional
+	Owners []string `yaml:"owners,omitempty" json:"owners,omitempty" toml:"owners,omitempty"`
+
+	// +optional
+	Managers []string `yaml:"managers,omitempty" json:"managers,omitempty" toml:"managers,omitempty"`
 
-	Settings map[string]string `yaml:"settings,omitempty" json:"settings,omitempty"`
+	// +optional
+	Members []string `yaml:"members,omitempty" json:"members,omitempty" toml:"members,omitempty"`
+
+	// MembershipPolicy controls how the owners, managers and members are
+	// reconciled: authoritative, owners-authoritative, additive or ignore.
+	// If not specified, it defaults to the membership-policy of the config.
+	// It replaces the deprecated ReconcileMembers setting.
+	// +optional
+	MembershipPolicy string `yaml:"membership-policy,omitempty" json:"membership-policy,omitempty" toml:"membership-policy,omitempty"`
 
+	// IgnoreMembers is the list of patterns of the emails the reconciler
+	// never adds, updates or removes in this group, on top of the
+	// ignore-members of the config. A pattern starting with "@" matches a
+	// domain; any other pattern is a regular expression matching the whole
+	// email.
 	// +optional
-	Owners []string `yaml:"owners,omitempty" json:"owners,omitempty"`
+	IgnoreMembers []string `yaml:"ignore-members,omitempty" json:"ignore-members,omitempty" toml:"ignore-members,omitempty"`
 
+	// Protected groups never have the bot-id of the tenant or the break-glass
+	// accounts of the config removed: reconciling their members fails
+	// instead.
 	// +optional
-	Managers []string `yaml:"managers,omitempty" json:"managers,omitempty"`
+	Protected bool `yaml:"protected,omitempty" json:"protected,omitempty" toml:"protected,omitempty"`
 
+	// Labels are free-form key/value pairs used to select groups with the
+	// --label flag. They are not reconciled.
 	// +optional
-	Members []string `yaml:"members,omitempty" json:"members,omitempty"`
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty" toml:"labels,omitempty"`
+
+	// Path is the path of the file the group is defined in, relative to the
+	// groups-path, with forward slashes. It is set when loading the config.
+	Path string `yaml:"-" json:"-" toml:"-"`
+
+	// Expires maps the owners, managers and members given an expiry date in
+	// the config, keyed by the expiryKey of their role and email, to that
+	// date. Their membership lasts until the end of that day, in UTC; after
+	// that they are treated as absent from the group. It is set when
+	// loading the config.
+	Expires map[string]time.Time `yaml:"-" json:"-" toml:"-"`
 }
 
 // RestrictionsConfig contains the list of restrictions for
@@ -100,103 +227,363 @@ type Restriction struct {
 	// Compiles to AllowedGroupsRe during config load.
 	AllowedGroups []string `yaml:"allowedGroups" json:"allowedGroups"`
 
-	AllowedGroupsRe []*regexp.Regexp
+	AllowedGroupsRe []*regexp.Regexp `yaml:"-" json:"-"`
 }
 
 func Usage() {
 	fmt.Fprintf(os.Stderr, `
-Usage: %s [-config <config-yaml-file>] [--confirm]
+Usage: %s [command] [-config <config-yaml-file>] [flags]
 Command line flags override config values.
+
+Commands:
+  reconcile  make the groups match the config (default) [--confirm] [--print]
+             [--group <email>] [--path <glob>] [--label <key=value>]
+             [--delete-groups] [--since <git-ref>]
+  export     write the live groups to a ready-to-commit groups tree --out <dir>
+  drift      report how the live groups differ from the config, exiting with
+             code 3 if they do
+  rollback   restore the groups to a snapshot taken before changes were
+             applied [--confirm] <snapshot-file>
+  validate   check the config without calling any API, failing on
+             memberships that already expired
+  whois      print the groups an email is in and with what role, including
+             through nested groups [--live] <email>
+  offboard   remove an email from every groups config file and print a
+             summary of the changes [--dry-run] <email>
+  fmt        sort the owners, managers and members of the YAML groups config
+             files and normalize their emails, keeping comments [--check]
+  audit      print the applied changes to a group or a member from the audit
+             log [--group <email>] [--member <email>]
+  serve      keep running and reconcile whenever the config changes
+             [--confirm] [--git-dir <dir>] [--listen <addr>], serving the
+             metrics on /metrics
 `, os.Args[0])
 	flag.PrintDefaults()
 }
 
 var (
-	config             Config
-	groupsConfig       GroupsConfig
-	restrictionsConfig RestrictionsConfig
+	config Config
 
-	verbose = flag.Bool("v", false, "log extra information")
+	logLevel  = flag.String("log-level", "info", "the minimum level of the logs: debug, info, warn or error")
+	logFormat = flag.String("log-format", TextLogFormat, "the format of the logs: text (logfmt) or json")
+	verbose   = flag.Bool("v", false, "log extra information, same as --log-level=debug")
 
 	defaultConfigFile       = "config.yaml"
+	defaultTenantName       = "default"
+	defaultCustomerID       = "my_customer"
 	defaultRestrictionsFile = "restrictions.yaml"
+	defaultGroupsFile       = "groups.yaml"
+	defaultIgnoreFile       = ".ggreconcileignore"
 	emptyRegexp             = regexp.MustCompile("")
 	defaultRestriction      = Restriction{Path: "*", AllowedGroupsRe: []*regexp.Regexp{emptyRegexp}}
 )
 
+// commands maps the name of each command to a function that registers the
+// flags of the command and returns the function running it.
+var commands = map[string]func() func(configFilePath string) error{
+	"reconcile": reconcileCommand,
+	"export":    exportCommand,
+	"drift":     driftCommand,
+	"serve":     serveCommand,
+	"audit":     auditCommand,
+	"rollback":  rollbackCommand,
+	"validate":  validateCommand,
+	"whois":     whoisCommand,
+	"offboard":  offboardCommand,
+	"fmt":       formatCommand,
+}
+
+// exitCodeError is returned by commands to make the tool exit with code
+// instead of the usual exit code of a failure.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
 func main() {
+	command, args := "reconcile", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	setup, ok := commands[command]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", command)
+		Usage()
+		os.Exit(2)
+	}
+
 	configFilePath := flag.String("config", defaultConfigFile, "the config file in yaml format")
-	confirmChanges := flag.Bool("confirm", false, "false by default means that we do not push anything to google groups")
-	printConfig := flag.Bool("print", false, "print the existing group information")
+	metricsFile := flag.String("metrics-file", "", "if set, write the metrics of the run to this file in the Prometheus text format, for the node exporter textfile collector or a pushgateway")
+	run := setup()
 
 	flag.Usage = Usage
-	flag.Parse()
-
-	if *printConfig {
-		log.Printf("print: %v -- disabling confirm, will print existing group information", *confirmChanges)
-		*confirmChanges = false
+	if err := flag.CommandLine.Parse(args); err != nil {
+		os.Exit(2)
 	}
-	if !*confirmChanges {
-		log.Printf("confirm: %v -- dry-run mode, changes will not be pushed", *confirmChanges)
+	if *verbose {
+		*logLevel = "debug"
+	}
+	if err := configureLogger(logger, os.Stderr, *logLevel, *logFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
-	err := config.Load(*configFilePath, *confirmChanges)
+	err := run(*configFilePath)
+	if *metricsFile != "" {
+		if err := prometheus.WriteToTextfile(*metricsFile, metricsRegistry); err != nil {
+			logger.WithError(err).Errorf("unable to write metrics to %s", *metricsFile)
+		}
+	}
 	if err != nil {
-		log.Fatal(err)
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			logger.Warn(err)
+			os.Exit(exitErr.code)
+		}
+		logger.Fatal(err)
 	}
+}
 
-	log.Printf("config: BotID:            %v", config.BotID)
-	log.Printf("config: SecretVersion:    %v", config.SecretVersion)
-	log.Printf("config: GroupsPath:       %v", config.GroupsPath)
-	log.Printf("config: RestrictionsPath: %v", config.RestrictionsPath)
-	log.Printf("config: ConfirmChanges:   %v", config.ConfirmChanges)
+// reconcileCommand registers the flags of the reconcile command, which makes
+// the groups match the config or prints their live state.
+func reconcileCommand() func(configFilePath string) error {
+	confirmChanges := flag.Bool("confirm", false, "false by default means that we do not push anything to google groups")
+	printConfig := flag.Bool("print", false, "print the existing group information")
+	var filter GroupFilter
+	flag.Var((*stringsFlag)(&filter.Groups), "group", "only reconcile the group with this email-id; can be repeated")
+	flag.Var((*stringsFlag)(&filter.Paths), "path", "only reconcile the groups defined in files matching this pattern relative to the groups-path, such as sig-foo/**; can be repeated")
+	flag.Var((*stringsFlag)(&filter.Labels), "label", "only reconcile the groups with this key=value label, or with this label key; can be repeated")
+	deleteGroups := flag.Bool("delete-groups", false, "delete the groups that are not in the config even when only some groups are reconciled with -group, -path or -label")
+	since := flag.String("since", "", "only reconcile the groups whose definition changed since this git ref, and only delete the groups removed from the config since then")
+
+	return func(configFilePath string) error {
+		if *printConfig {
+			logger.Infof("print: %v -- disabling confirm, will print existing group information", *confirmChanges)
+			*confirmChanges = false
+		}
+		if !*confirmChanges {
+			logger.Infof("confirm: %v -- dry-run mode, changes will not be pushed", *confirmChanges)
+		}
 
-	err = restrictionsConfig.Load(config.RestrictionsPath)
-	if err != nil {
-		log.Fatal(err)
+		if err := filter.Validate(); err != nil {
+			return err
+		}
+		if *since != "" && (!filter.IsEmpty() || *deleteGroups) {
+			return fmt.Errorf("-since cannot be combined with -group, -path, -label or -delete-groups")
+		}
+		if !filter.IsEmpty() && !*deleteGroups {
+			logger.Info("only reconciling the selected groups, groups that are not in the config will not be deleted")
+		}
+
+		if err := loadConfig(configFilePath, *confirmChanges); err != nil {
+			return err
+		}
+
+		mode := PlanMode
+		switch {
+		case *printConfig:
+			mode = PrintMode
+		case config.ConfirmChanges:
+			mode = ApplyMode
+		}
+
+		return forEachTenant(mode, func(t *Tenant, r *Reconciler) (string, error) {
+			if mode == PrintMode {
+				if len(config.Tenants) > 1 {
+					fmt.Printf("---\n# tenant: %s\n", t.Name)
+				}
+				return "", r.printGroupMembersAndSettings()
+			}
+
+			groupsConfig, err := loadTenantGroups(t)
+			if err != nil {
+				return "", err
+			}
+
+			if *since != "" {
+				opts := config.GroupsLoadOptions()
+				changes, err := ChangesSince(t.GroupsPath, *since, opts, groupsConfig.Groups)
+				if err != nil {
+					return "", err
+				}
+				r.log.WithField("since", *since).Infof("changed groups: %v, deleted groups: %v", changes.Changed, changes.Deleted)
+				p := ApplyPlan{Config: groupsConfig.Groups, Deleted: changes.Deleted}
+				if len(changes.Changed) > 0 {
+					p.Groups = GroupFilter{Groups: changes.Changed}.Select(groupsConfig.Groups)
+				}
+				if err := snapshotBeforeApply(t, r, p); err != nil {
+					return "", err
+				}
+				summary := fmt.Sprintf("%d changed and %d deleted groups since %s", len(changes.Changed), len(changes.Deleted), *since)
+				return summary, r.ReconcileChanges(groupsConfig.Groups, changes)
+			}
+			if filter.IsEmpty() {
+				if err := snapshotBeforeApply(t, r, fullApplyPlan(groupsConfig.Groups)); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d groups", len(groupsConfig.Groups)), r.ReconcileGroups(groupsConfig.Groups)
+			}
+			p := ApplyPlan{
+				Config:             groupsConfig.Groups,
+				Groups:             filter.Select(groupsConfig.Groups),
+				DeleteUnconfigured: *deleteGroups,
+			}
+			if err := snapshotBeforeApply(t, r, p); err != nil {
+				return "", err
+			}
+			summary := fmt.Sprintf("%d of %d groups", len(p.Groups), len(groupsConfig.Groups))
+			return summary, r.ReconcileSelectedGroups(groupsConfig.Groups, filter, *deleteGroups)
+		})
 	}
+}
 
-	err = groupsConfig.Load(config.GroupsPath, &restrictionsConfig)
+// loadConfig loads the global config from configFilePath and logs it.
+func loadConfig(configFilePath string, confirmChanges bool) error {
+	err := config.Load(configFilePath, confirmChanges)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	serviceAccountKey, err := accessSecretVersion(config.SecretVersion)
+	logger.WithFields(logrus.Fields{
+		"groupsFiles":    config.GroupsFilePatterns,
+		"followSymlinks": config.FollowSymlinks,
+		"confirmChanges": config.ConfirmChanges,
+		"tenants":        len(config.Tenants),
+	}).Info("loaded config")
+	return nil
+}
+
+// forEachTenant creates a Reconciler for each tenant in the config, with the
+// OAuth scopes needed by mode, and passes it to fn. The errors that occured in
+// each tenant are aggregated and returned together in the end, so one broken
+// tenant doesn't block the others. Unless running in PrintMode, a summary
+// made of the description returned by fn for each tenant is logged.
+func forEachTenant(mode string, fn func(t *Tenant, r *Reconciler) (string, error)) error {
+	scopes, err := ScopesForMode(mode)
 	if err != nil {
-		log.Fatalf("Unable to access secret-version %s, %v", config.SecretVersion, err)
+		return err
+	}
+	logger.WithField("mode", mode).Infof("requesting scopes %v", scopes)
+
+	ctx := context.Background()
+
+	// Changes are only audited when they are applied.
+	var auditLog *AuditLog
+	runID := newRunID()
+	if mode == ApplyMode && config.AuditLogPath != "" {
+		auditLog = NewAuditLog(config.AuditLogPath)
+		logger.WithField("runID", runID).Infof("auditing changes to %s", config.AuditLogPath)
+	}
+
+	var (
+		errs      []error
+		summaries []string
+	)
+	for i := range config.Tenants {
+		t := &config.Tenants[i]
+		var audit *tenantAudit
+		if auditLog != nil {
+			audit = &tenantAudit{log: auditLog, base: AuditEntry{RunID: runID, ConfigCommit: gitCommit(t.GroupsPath), Tenant: t.Name}}
+		}
+		start := time.Now()
+		summary, err := runTenant(ctx, t, scopes, audit, fn)
+		if mode != PrintMode {
+			recordRun(t.Name, mode, start, err)
+		}
+		if summary == "" {
+			summary = "done"
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t.Name, err))
+			summaries = append(summaries, fmt.Sprintf("tenant %s: %s, failed: %v", t.Name, summary, err))
+			continue
+		}
+		summaries = append(summaries, fmt.Sprintf("tenant %s: %s, ok", t.Name, summary))
+	}
+
+	if mode != PrintMode {
+		for _, s := range summaries {
+			logger.Info(s)
+		}
 	}
+	return utilerrors.NewAggregate(errs)
+}
 
-	credential, err := google.JWTConfigFromJSON(serviceAccountKey, admin.AdminDirectoryUserReadonlyScope,
-		admin.AdminDirectoryGroupScope,
-		admin.AdminDirectoryGroupMemberScope,
-		groupssettings.AppsGroupsSettingsScope)
+// uniqueGroupsPathTenants returns the first tenant of the config for each
+// groups-path, as tenants may share theirs, for the commands that work on
+// the groups config files rather than the live groups.
+func uniqueGroupsPathTenants() []*Tenant {
+	var tenants []*Tenant
+	done := map[string]bool{}
+	for i := range config.Tenants {
+		t := &config.Tenants[i]
+		if done[t.GroupsPath] {
+			continue
+		}
+		done[t.GroupsPath] = true
+		tenants = append(tenants, t)
+	}
+	return tenants
+}
+
+// runTenant creates a Reconciler for the tenant t authorized for scopes,
+// auditing its changes to audit if not nil, and passes it to fn.
+func runTenant(ctx context.Context, t *Tenant, scopes []string, audit *tenantAudit, fn func(t *Tenant, r *Reconciler) (string, error)) (string, error) {
+	log := logger.WithField(tenantField, t.Name)
+	log.WithFields(logrus.Fields{
+		"customerID":       t.CustomerID,
+		"domain":           t.Domain,
+		"managedDomains":   t.ManagedDomains,
+		"botID":            t.BotID,
+		"secretVersion":    t.SecretVersion,
+		"credentials":      t.Credentials.Source,
+		"apiEndpoint":      t.APIEndpoint,
+		"groupsPath":       t.GroupsPath,
+		"restrictionsPath": t.RestrictionsPath,
+	}).Info("running tenant")
+
+	provider, err := NewCredentialProvider(t)
 	if err != nil {
-		log.Fatalf("Unable to authenticate using key in secret-version %s, %v", config.SecretVersion, err)
+		return "", err
 	}
-	credential.Subject = config.BotID
 
-	ctx := context.Background()
-	client := credential.Client(ctx)
+	client, err := provider.Client(ctx, t.BotID, scopes...)
+	if err != nil {
+		return "", err
+	}
 	clientOption := option.WithHTTPClient(client)
 
-	r, err := NewReconciler(ctx, clientOption)
+	r, err := NewReconciler(ctx, t, log, audit, clientOption)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
-	if *printConfig {
-		err = r.printGroupMembersAndSettings()
-		if err != nil {
-			log.Fatal(err)
-		}
-		return
+	return fn(t, r)
+}
+
+// loadTenantGroups loads the restrictions and the groups config of the
+// tenant t.
+func loadTenantGroups(t *Tenant) (*GroupsConfig, error) {
+	var (
+		restrictionsConfig RestrictionsConfig
+		groupsConfig       GroupsConfig
+	)
+	err := restrictionsConfig.Load(t.RestrictionsPath)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Println(" ======================= Updates =======================")
-	err = r.ReconcileGroups(groupsConfig.Groups)
+	opts := config.GroupsLoadOptions()
+	opts.ManagedDomains = t.ManagedDomains
+	err = groupsConfig.Load(t.GroupsPath, opts, &restrictionsConfig)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	return &groupsConfig, nil
 }
 
 // Reconciler syncs the actual state of the world with the configuration.
@@ -205,29 +592,52 @@ func main() {
 type Reconciler struct {
 	adminService AdminService
 	groupService GroupService
+	log          *logrus.Entry
+	// botID is the email of the account making the changes, which is never
+	// removed from protected groups.
+	botID string
 }
 
-func NewReconciler(ctx context.Context, clientOption option.ClientOption) (*Reconciler, error) {
-	as, err := NewAdminService(ctx, clientOption)
+// NewReconciler returns a Reconciler for the groups of the tenant t, logging
+// to log and auditing the changes it applies to audit if not nil.
+func NewReconciler(ctx context.Context, t *Tenant, log *logrus.Entry, audit *tenantAudit, clientOption option.ClientOption) (*Reconciler, error) {
+	as, err := NewAdminService(ctx, t, log, audit, clientOption)
 	if err != nil {
 		return nil, err
 	}
 
-	gs, err := NewGroupService(ctx, clientOption)
+	gs, err := NewGroupService(ctx, t, log, audit, clientOption)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Reconciler{adminService: as, groupService: gs}, nil
+	return &Reconciler{adminService: as, groupService: gs, log: log, botID: t.BotID}, nil
 }
 
 func (r *Reconciler) ReconcileGroups(groups []GoogleGroup) error {
+	return r.ReconcileSelectedGroups(groups, GroupFilter{}, true)
+}
+
+// ReconcileSelectedGroups reconciles the groups of the config selected by
+// filter. Groups that are not in the config are only deleted if
+// deleteGroups is true, in which case every group of the config is kept, not
+// only the selected ones.
+func (r *Reconciler) ReconcileSelectedGroups(groups []GoogleGroup, filter GroupFilter, deleteGroups bool) error {
 	// aggregate the errors that occured and return them together in the end.
 	var errs []error
-	for _, g := range groups {
+	now := time.Now()
+	for _, g := range filter.Select(groups) {
 		if g.EmailId == "" {
 			errs = append(errs, fmt.Errorf("group has no email-id: %#v", g))
 		}
+		r.log.WithField(groupField, g.EmailId).Debug("reconciling group")
+		logExpiringMembers(r.log, g, now, config.ExpiryWarningDays)
+		g = g.withoutExpiredMembers(now)
+		policy := g.membershipPolicy()
+		if err := r.checkLockout(g, policy); err != nil {
+			errs = append(errs, err)
+			continue
+		}
 
 		err := r.adminService.CreateOrUpdateGroupIfNescessary(g)
 		if err != nil {
@@ -239,6 +649,11 @@ func (r *Reconciler) ReconcileGroups(groups []GoogleGroup) error {
 			errs = append(errs, err)
 		}
 
+		if policy == IgnorePolicy {
+			r.log.WithField(groupField, g.EmailId).Debug("leaving the members alone as the membership-policy is ignore")
+			continue
+		}
+
 		err = r.adminService.AddOrUpdateGroupMembers(g, OwnerRole, g.Owners)
 		if err != nil {
 			errs = append(errs, err)
@@ -254,14 +669,15 @@ func (r *Reconciler) ReconcileGroups(groups []GoogleGroup) error {
 			errs = append(errs, err)
 		}
 
-		if g.Settings["ReconcileMembers"] == "true" {
+		switch policy {
+		case AuthoritativePolicy:
 			members := append(g.Owners, g.Managers...)
 			members = append(members, g.Members...)
 			err = r.adminService.RemoveMembersFromGroup(g, members)
 			if err != nil {
 				errs = append(errs, err)
 			}
-		} else {
+		case OwnersAuthoritativePolicy:
 			members := append(g.Owners, g.Managers...)
 			err = r.adminService.RemoveOwnerOrManagersFromGroup(g, members)
 			if err != nil {
@@ -270,7 +686,11 @@ func (r *Reconciler) ReconcileGroups(groups []GoogleGroup) error {
 		}
 	}
 
-	err := r.adminService.DeleteGroupsIfNecessary()
+	if !deleteGroups {
+		r.log.Info("skipping the deletion of groups that are not in the config")
+		return utilerrors.NewAggregate(errs)
+	}
+	err := r.adminService.DeleteGroupsIfNecessary(groups)
 	if err != nil {
 		errs = append(errs, err)
 	}
@@ -278,14 +698,29 @@ func (r *Reconciler) ReconcileGroups(groups []GoogleGroup) error {
 	return utilerrors.NewAggregate(errs)
 }
 
-func (r *Reconciler) printGroupMembersAndSettings() error {
+// liveGroups returns the live state of the groups listed by the admin service
+// in the form of the config that would reconcile to it: settings equal to
+// their defaults are left out and members are sorted. It is the inverse of
+// ReconcileGroups, which is used both by export and print.
+func (r *Reconciler) liveGroups() ([]GoogleGroup, error) {
+	return r.liveGroupsWithSettings(nonDefaultSettings, nil)
+}
+
+// liveGroupsWithSettings returns the live state of the groups like
+// liveGroups, keeping the settings returned by settings for each group. If
+// include is not nil, only the groups whose email it returns true for are
+// read.
+func (r *Reconciler) liveGroupsWithSettings(settings func(*groupssettings.Groups) map[string]string, include func(email string) bool) ([]GoogleGroup, error) {
 	g, err := r.adminService.ListGroups()
 	if err != nil {
-		return fmt.Errorf("unable to retrieve users in domain: %w", err)
+		return nil, fmt.Errorf("unable to retrieve users in domain: %w", err)
 	}
 
-	var groupsConfig GroupsConfig
+	var groups []GoogleGroup
 	for _, g := range g.Groups {
+		if include != nil && !include(g.Email) {
+			continue
+		}
 		group := GoogleGroup{
 			EmailId:     g.Email,
 			Name:        g.Name,
@@ -293,24 +728,15 @@ func (r *Reconciler) printGroupMembersAndSettings() error {
 		}
 		g2, err := r.groupService.Get(g.Email)
 		if err != nil {
-			return fmt.Errorf("unable to retrieve group info for group %s: %w", g.Email, err)
-		}
-		group.Settings = make(map[string]string)
-		group.Settings["AllowExternalMembers"] = g2.AllowExternalMembers
-		group.Settings["WhoCanJoin"] = g2.WhoCanJoin
-		group.Settings["WhoCanViewMembership"] = g2.WhoCanViewMembership
-		group.Settings["WhoCanViewGroup"] = g2.WhoCanViewGroup
-		group.Settings["WhoCanDiscoverGroup"] = g2.WhoCanDiscoverGroup
-		group.Settings["WhoCanInvite"] = g2.WhoCanInvite
-		group.Settings["WhoCanAdd"] = g2.WhoCanAdd
-		group.Settings["WhoCanApproveMembers"] = g2.WhoCanApproveMembers
-		group.Settings["WhoCanModifyMembers"] = g2.WhoCanModifyMembers
-		group.Settings["WhoCanModerateMembers"] = g2.WhoCanModerateMembers
-		group.Settings["MembersCanPostAsTheGroup"] = g2.MembersCanPostAsTheGroup
+			return nil, fmt.Errorf("unable to retrieve group info for group %s: %w", g.Email, err)
+		}
+		if settings := settings(g2); len(settings) > 0 {
+			group.Settings = settings
+		}
 
 		l, err := r.adminService.ListMembers(g.Email)
 		if err != nil {
-			return fmt.Errorf("unable to retrieve members in group : %w", err)
+			return nil, fmt.Errorf("unable to retrieve members in group : %w", err)
 		}
 
 		for _, m := range l.Members {
@@ -323,22 +749,46 @@ func (r *Reconciler) printGroupMembersAndSettings() error {
 				group.Members = append(group.Members, m.Email)
 			}
 		}
+		sort.Strings(group.Owners)
+		sort.Strings(group.Managers)
+		sort.Strings(group.Members)
 
-		groupsConfig.Groups = append(groupsConfig.Groups, group)
+		groups = append(groups, group)
 	}
+	return groups, nil
+}
 
-	cm := genyaml.NewCommentMap("reconcile.go")
-	yamlSnippet, err := cm.GenYaml(groupsConfig)
+// printGroupMembersAndSettings prints the live state of the groups as a
+// GroupsConfig that reconciles to that same state without any changes.
+func (r *Reconciler) printGroupMembersAndSettings() error {
+	yamlSnippet, err := r.liveGroupsYAML()
 	if err != nil {
-		return fmt.Errorf("unable to generate yaml for groups : %w", err)
+		return err
 	}
 
 	fmt.Println(yamlSnippet)
 	return nil
 }
 
+// liveGroupsYAML returns the live state of the groups as a commented
+// GroupsConfig in yaml format.
+func (r *Reconciler) liveGroupsYAML() (string, error) {
+	groups, err := r.liveGroups()
+	if err != nil {
+		return "", err
+	}
+	groupsConfig := GroupsConfig{Groups: groups}
+
+	cm := genyaml.NewCommentMap("reconcile.go")
+	yamlSnippet, err := cm.GenYaml(groupsConfig)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate yaml for groups : %w", err)
+	}
+	return yamlSnippet, nil
+}
+
 func (c *Config) Load(configFilePath string, confirmChanges bool) error {
-	log.Printf("reading config file: %s", configFilePath)
+	logger.WithField("path", configFilePath).Info("reading config file")
 	content, err := ioutil.ReadFile(configFilePath)
 	if err != nil {
 		return fmt.Errorf("error reading config file %s: %w", configFilePath, err)
@@ -347,28 +797,142 @@ func (c *Config) Load(configFilePath string, confirmChanges bool) error {
 		return fmt.Errorf("error parsing config file %s: %w", configFilePath, err)
 	}
 
-	if c.GroupsPath == "" {
-		c.GroupsPath, err = filepath.Abs(filepath.Dir(configFilePath))
-		if err != nil {
-			return fmt.Errorf("error converting groups-path %v to absolute path: %w", c.GroupsPath, err)
+	configDir, err := filepath.Abs(filepath.Dir(configFilePath))
+	if err != nil {
+		return fmt.Errorf("error converting config directory to absolute path: %w", err)
+	}
+
+	if len(c.Tenants) == 0 {
+		c.Tenants = []Tenant{c.Tenant}
+		if c.Tenants[0].Name == "" {
+			c.Tenants[0].Name = defaultTenantName
+		}
+	}
+	names := map[string]bool{}
+	for i := range c.Tenants {
+		t := &c.Tenants[i]
+		if t.Name == "" {
+			return fmt.Errorf("tenant %d has no name", i)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("duplicate tenant name %q", t.Name)
+		}
+		names[t.Name] = true
+
+		t.inheritFrom(&c.Tenant)
+		if err := t.setDefaults(configDir); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.Name, err)
+		}
+	}
+
+	if c.AuditLogPath != "" && !filepath.IsAbs(c.AuditLogPath) {
+		c.AuditLogPath = filepath.Join(configDir, c.AuditLogPath)
+	}
+	if c.SoftDelete != nil {
+		if err := c.SoftDelete.setDefaults(configDir); err != nil {
+			return err
 		}
 	}
-	if !filepath.IsAbs(config.GroupsPath) {
-		return fmt.Errorf("groups-path must be an absolute path, got: %v ", c.GroupsPath)
+	if c.MembershipPolicy == "" {
+		c.MembershipPolicy = OwnersAuthoritativePolicy
+	} else if !validMembershipPolicy(c.MembershipPolicy) {
+		return fmt.Errorf("unknown membership-policy %q", c.MembershipPolicy)
+	}
+	if c.IgnoreMembersRe, err = compileIgnoreMembers(c.IgnoreMembers); err != nil {
+		return err
+	}
+	if c.ExpiryWarningDays == 0 {
+		c.ExpiryWarningDays = defaultExpiryWarningDays
+	}
+	if c.SnapshotDir == "" {
+		c.SnapshotDir = defaultSnapshotDir()
+	} else if !filepath.IsAbs(c.SnapshotDir) {
+		c.SnapshotDir = filepath.Join(configDir, c.SnapshotDir)
 	}
 
-	if c.RestrictionsPath == "" {
-		c.RestrictionsPath = filepath.Join(c.GroupsPath, defaultRestrictionsFile)
+	if len(c.GroupsFilePatterns) == 0 {
+		c.GroupsFilePatterns = []string{defaultGroupsFile}
+	}
+	for _, p := range c.GroupsFilePatterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid groups-file-patterns entry %q: %w", p, err)
+		}
 	}
 
 	c.ConfirmChanges = confirmChanges
-	return err
+	return nil
+}
+
+// inheritFrom copies the fields that are not set in t from defaults.
+func (t *Tenant) inheritFrom(defaults *Tenant) {
+	if t.CustomerID == "" && t.Domain == "" && len(t.ManagedDomains) == 0 {
+		t.CustomerID = defaults.CustomerID
+		t.Domain = defaults.Domain
+		t.ManagedDomains = defaults.ManagedDomains
+	}
+	if t.BotID == "" {
+		t.BotID = defaults.BotID
+	}
+	if t.SecretVersion == "" {
+		t.SecretVersion = defaults.SecretVersion
+	}
+	if t.Credentials == (CredentialsConfig{}) {
+		t.Credentials = defaults.Credentials
+	}
+	if t.APIEndpoint == "" {
+		t.APIEndpoint = defaults.APIEndpoint
+	}
+	if t.GroupsPath == "" {
+		t.GroupsPath = defaults.GroupsPath
+	}
+	if t.RestrictionsPath == "" {
+		t.RestrictionsPath = defaults.RestrictionsPath
+	}
+}
+
+// listDomains returns the domains whose groups are listed separately, or nil
+// if the groups of the whole customer are listed at once.
+func (t *Tenant) listDomains() []string {
+	if len(t.ManagedDomains) > 0 {
+		return t.ManagedDomains
+	}
+	if t.Domain != "" {
+		return []string{t.Domain}
+	}
+	return nil
+}
+
+// setDefaults fills in the defaults for the fields that are not set in t,
+// using configDir as the default groups-path, and validates the result.
+func (t *Tenant) setDefaults(configDir string) error {
+	if len(t.ManagedDomains) == 0 && t.Domain != "" {
+		t.ManagedDomains = []string{t.Domain}
+	}
+	if t.CustomerID == "" && len(t.ManagedDomains) == 0 {
+		t.CustomerID = defaultCustomerID
+	}
+
+	if t.GroupsPath == "" {
+		t.GroupsPath = configDir
+	}
+	if !filepath.IsAbs(t.GroupsPath) {
+		return fmt.Errorf("groups-path must be an absolute path, got: %v ", t.GroupsPath)
+	}
+
+	if t.RestrictionsPath == "" {
+		t.RestrictionsPath = filepath.Join(t.GroupsPath, defaultRestrictionsFile)
+	}
+
+	if t.Credentials.Source == "" {
+		t.Credentials.Source = SecretManagerSource
+	}
+	return nil
 }
 
 // Load populates the RestrictionsConfig with data parsed from path and returns
 // nil if successful, or an error otherwise
 func (rc *RestrictionsConfig) Load(path string) error {
-	log.Printf("reading restrictions config file: %s", path)
+	logger.WithField("path", path).Info("reading restrictions config file")
 	content, err := ioutil.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("error reading restrictions config file %s: %w", path, err)
@@ -393,41 +957,84 @@ func (rc *RestrictionsConfig) Load(path string) error {
 	return err
 }
 
-// readGroupsConfig starts at the rootDir and recursively walksthrough
-// all directories and files. It reads the GroupsConfig from all groups.yaml
-// files and verifies that the groups in GroupsConfig satisfy the
-// restrictions in restrictionsConfig.
-// Finally, it adds all the groups in each GroupsConfig to config.Groups.
-func (gc *GroupsConfig) Load(rootDir string, restrictions *RestrictionsConfig) error {
-	log.Printf("reading groups.yaml files recursively at %s", rootDir)
+// Load starts at the rootDir and recursively walks through all directories
+// and files in lexical order, skipping anything excluded by an ignore file at
+// the rootDir. It reads the GroupsConfig from all files whose base name matches
+// one of opts.Patterns and verifies that the groups in each GroupsConfig
+// satisfy the restrictions in restrictionsConfig.
+// Finally, it adds all the groups in each GroupsConfig to gc.Groups.
+//
+// Errors for individual files or directories do not stop the walk; they are
+// aggregated and returned together so that a partially loaded config is never
+// mistaken for a complete one.
+func (gc *GroupsConfig) Load(rootDir string, opts GroupsLoadOptions, restrictions *RestrictionsConfig) error {
+	if len(opts.Patterns) == 0 {
+		opts.Patterns = []string{defaultGroupsFile}
+	}
+	logger.WithField("path", rootDir).Infof("reading %s files recursively", strings.Join(opts.Patterns, ", "))
 
-	return filepath.Walk(rootDir, func(path string, info os.FileInfo, _ error) error {
-		if filepath.Base(path) == "groups.yaml" {
-			cleanPath := strings.Trim(strings.TrimPrefix(path, rootDir), string(filepath.Separator))
-			log.Printf("groups: %s", cleanPath)
+	ignore, err := loadIgnoreFile(filepath.Join(rootDir, defaultIgnoreFile))
+	if err != nil {
+		return err
+	}
 
-			var (
-				groupsConfigAtPath GroupsConfig
-				content            []byte
-				err                error
-			)
+	paths, errs := findGroupsFiles(rootDir, opts, ignore)
+	for _, path := range paths {
+		cleanPath := strings.Trim(strings.TrimPrefix(path, rootDir), string(filepath.Separator))
+		logger.WithField("path", cleanPath).Debug("reading groups file")
 
-			if content, err = ioutil.ReadFile(path); err != nil {
-				return fmt.Errorf("error reading groups config file %s: %w", path, err)
-			}
-			if err = yaml.Unmarshal(content, &groupsConfigAtPath); err != nil {
-				return fmt.Errorf("error parsing groups config at %s: %w", path, err)
-			}
+		var groupsConfigAtPath GroupsConfig
 
-			r := restrictions.GetRestrictionForPath(path, rootDir)
-			mergedGroups, err := mergeGroups(gc.Groups, groupsConfigAtPath.Groups, r)
-			if err != nil {
-				return fmt.Errorf("couldn't merge groups: %w", err)
-			}
-			gc.Groups = mergedGroups
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error reading groups config file %s: %w", path, err))
+			continue
+		}
+		if err = unmarshalGroupsConfig(path, content, &groupsConfigAtPath); err != nil {
+			errs = append(errs, fmt.Errorf("error parsing groups config at %s: %w", path, err))
+			continue
+		}
+
+		if err := checkManagedDomains(groupsConfigAtPath.Groups, opts.ManagedDomains); err != nil {
+			errs = append(errs, fmt.Errorf("invalid groups config at %s: %w", path, err))
+			continue
+		}
+		for i := range groupsConfigAtPath.Groups {
+			groupsConfigAtPath.Groups[i].Path = filepath.ToSlash(cleanPath)
+		}
+		if err := utilerrors.NewAggregate([]error{
+			checkMembershipPolicies(groupsConfigAtPath.Groups),
+			checkIgnoreMembers(groupsConfigAtPath.Groups),
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("invalid groups config at %s: %w", path, err))
+			continue
 		}
-		return nil
-	})
+
+		r := restrictions.GetRestrictionForPath(path, rootDir)
+		mergedGroups, err := mergeGroups(gc.Groups, groupsConfigAtPath.Groups, r)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("couldn't merge groups from %s: %w", path, err))
+			continue
+		}
+		gc.Groups = mergedGroups
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// unmarshalGroupsConfig decodes content into gc using the format implied by
+// the extension of path.
+func unmarshalGroupsConfig(path string, content []byte, gc *GroupsConfig) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(content, gc)
+	case ".json":
+		return json.Unmarshal(content, gc)
+	case ".toml":
+		return toml.Unmarshal(content, gc)
+	default:
+		return fmt.Errorf("unsupported groups config format %q", ext)
+	}
 }
 
 // GetRestrictionForPath returns the first Restriction whose Path matches the
@@ -462,32 +1069,41 @@ func mergeGroups(a []GoogleGroup, b []GoogleGroup, r Restriction) ([]GoogleGroup
 	return append(a, b...), nil
 }
 
-func matchesRegexList(s string, list []*regexp.Regexp) bool {
-	for _, r := range list {
-		if r.MatchString(s) {
-			return true
+// checkManagedDomains returns an error listing the groups whose email-id is
+// not in one of the managed domains.
+func checkManagedDomains(groups []GoogleGroup, domains []string) error {
+	var errs []error
+	for _, g := range groups {
+		if !inManagedDomains(g.EmailId, domains) {
+			errs = append(errs, fmt.Errorf("group %q is outside the managed domains %v", g.EmailId, domains))
 		}
 	}
-	return false
+	return utilerrors.NewAggregate(errs)
 }
 
-// accessSecretVersion accesses the payload for the given secret version if one exists
-// secretVersion is of the form projects/{project}/secrets/{secret}/versions/{version}
-func accessSecretVersion(secretVersion string) ([]byte, error) {
-	ctx := context.Background()
-	client, err := secretmanager.NewClient(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create secretmanager client: %w", err)
+// inManagedDomains reports whether the domain of email is one of domains.
+// Every email is considered managed if domains is empty.
+func inManagedDomains(email string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
 	}
-
-	req := &secretmanagerpb.AccessSecretVersionRequest{
-		Name: secretVersion,
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
 	}
-
-	result, err := client.AccessSecretVersion(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to access secret version: %w", err)
+	for _, d := range domains {
+		if strings.EqualFold(email[at+1:], d) {
+			return true
+		}
 	}
+	return false
+}
 
-	return result.Payload.Data, nil
+func matchesRegexList(s string, list []*regexp.Regexp) bool {
+	for _, r := range list {
+		if r.MatchString(s) {
+			return true
+		}
+	}
+	return false
 }
@@ -17,8 +17,15 @@ limitations under the License.
 package main
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"gopkg.in/yaml.v3"
 )
 
 func TestRestrictionForPath(t *testing.T) {
@@ -80,3 +87,264 @@ func TestRestrictionForPath(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupsConfigLoadFormats(t *testing.T) {
+	rootDir := t.TempDir()
+	files := map[string]string{
+		"groups.yaml":               "groups:\n  - email-id: yaml@example.com\n    name: yaml\n",
+		"sig-json/json.groups.json": `{"groups": [{"email-id": "json@example.com", "name": "json", "owners": ["a@example.com"]}]}`,
+		"sig-toml/toml.groups.toml": "[[groups]]\nemail-id = \"toml@example.com\"\nname = \"toml\"\n[groups.settings]\nReconcileMembers = \"true\"\n",
+		"sig-skip/other.yaml":       "not: [a groups file",
+	}
+	writeTree(t, rootDir, files)
+
+	var gc GroupsConfig
+	opts := GroupsLoadOptions{Patterns: []string{"groups.yaml", "*.groups.json", "*.groups.toml"}}
+	if err := gc.Load(rootDir, opts, &RestrictionsConfig{}); err != nil {
+		t.Fatalf("unexpected error loading groups: %v", err)
+	}
+
+	got := map[string]GoogleGroup{}
+	for _, g := range gc.Groups {
+		got[g.EmailId] = g
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 groups, got %d: %v", len(got), gc.Groups)
+	}
+	if owners := got["json@example.com"].Owners; !reflect.DeepEqual(owners, []string{"a@example.com"}) {
+		t.Errorf("unexpected owners for json group: %v", owners)
+	}
+	if v := got["toml@example.com"].Settings["ReconcileMembers"]; v != "true" {
+		t.Errorf("unexpected ReconcileMembers setting for toml group: %q", v)
+	}
+}
+
+func TestGroupsConfigLoadErrors(t *testing.T) {
+	rootDir := t.TempDir()
+	files := map[string]string{
+		".ggreconcileignore":        "# generated\nvendor/\n",
+		"groups.yaml":               "groups:\n  - email-id: root@example.com\n",
+		"sig-a/groups.yaml":         "groups: [",
+		"sig-b/groups.yaml":         "groups:\n  - name: no-email\n",
+		"vendor/groups.yaml":        "groups: [",
+		"vendor/nested/groups.yaml": "groups: [",
+	}
+	writeTree(t, rootDir, files)
+	if err := os.Symlink(filepath.Join(rootDir, "sig-a"), filepath.Join(rootDir, "sig-link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(rootDir, "sig-b", "groups.yaml"), filepath.Join(rootDir, "sig-a", "groups.yaml.bak")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(rootDir, "missing.yaml"), filepath.Join(rootDir, "groups.yml")); err != nil {
+		t.Fatal(err)
+	}
+
+	var gc GroupsConfig
+	err := gc.Load(rootDir, GroupsLoadOptions{Patterns: []string{"groups.yaml", "groups.yml"}}, &RestrictionsConfig{})
+	if err == nil {
+		t.Fatal("expected an error loading groups")
+	}
+	for _, want := range []string{"sig-a/groups.yaml", "sig-b/groups.yaml", "sig-link", "groups.yml"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %s, got: %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "groups.yaml.bak") {
+		t.Errorf("expected a symlink to a file not matching the patterns to be skipped, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "vendor") {
+		t.Errorf("expected ignored subtree to be skipped, got: %v", err)
+	}
+	if len(gc.Groups) != 1 || gc.Groups[0].EmailId != "root@example.com" {
+		t.Errorf("expected only the root group to be loaded, got: %v", gc.Groups)
+	}
+}
+
+func TestGroupsConfigLoadFollowSymlinks(t *testing.T) {
+	rootDir := t.TempDir()
+	sharedDir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(sharedDir, "groups.yaml"), []byte("groups:\n  - email-id: shared@example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(sharedDir, filepath.Join(rootDir, "shared")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(rootDir, filepath.Join(sharedDir, "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	var gc GroupsConfig
+	err := gc.Load(rootDir, GroupsLoadOptions{FollowSymlinks: true}, &RestrictionsConfig{})
+	if err == nil || !strings.Contains(err.Error(), "symlink cycle") {
+		t.Errorf("expected a symlink cycle error, got: %v", err)
+	}
+	if len(gc.Groups) != 1 || gc.Groups[0].EmailId != "shared@example.com" {
+		t.Errorf("expected the group behind the symlink to be loaded, got: %v", gc.Groups)
+	}
+}
+
+func TestConfigLoadTenants(t *testing.T) {
+	configDir := t.TempDir()
+	testcases := []struct {
+		name     string
+		content  string
+		expected []Tenant
+	}{
+		{
+			name:    "single tenant",
+			content: "bot-id: bot@example.com\nsecret-version: projects/p/secrets/s/versions/1\n",
+			expected: []Tenant{
+				{
+					Name:             "default",
+					CustomerID:       "my_customer",
+					BotID:            "bot@example.com",
+					SecretVersion:    "projects/p/secrets/s/versions/1",
+					Credentials:      CredentialsConfig{Source: SecretManagerSource},
+					GroupsPath:       configDir,
+					RestrictionsPath: filepath.Join(configDir, "restrictions.yaml"),
+				},
+			},
+		},
+		{
+			name: "multiple tenants",
+			content: `
+bot-id: bot@example.com
+credentials:
+  source: key-file
+  key-file: /etc/key.json
+tenants:
+  - name: prod
+    customer-id: C012345
+    groups-path: /groups/prod
+  - name: staging
+    domain: staging.example.com
+    bot-id: bot@staging.example.com
+    credentials:
+      source: adc
+    groups-path: /groups/staging
+    restrictions-path: /groups/restrictions.yaml
+`,
+			expected: []Tenant{
+				{
+					Name:             "prod",
+					CustomerID:       "C012345",
+					BotID:            "bot@example.com",
+					Credentials:      CredentialsConfig{Source: KeyFileSource, KeyFile: "/etc/key.json"},
+					GroupsPath:       "/groups/prod",
+					RestrictionsPath: "/groups/prod/restrictions.yaml",
+				},
+				{
+					Name:             "staging",
+					Domain:           "staging.example.com",
+					ManagedDomains:   []string{"staging.example.com"},
+					BotID:            "bot@staging.example.com",
+					Credentials:      CredentialsConfig{Source: ADCSource},
+					GroupsPath:       "/groups/staging",
+					RestrictionsPath: "/groups/restrictions.yaml",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(configDir, "config.yaml")
+			if err := ioutil.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var c Config
+			if err := c.Load(path, false); err != nil {
+				t.Fatalf("unexpected error loading config: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expected, c.Tenants) {
+				t.Errorf("unexpected tenants: expected %+v, got %+v", tc.expected, c.Tenants)
+			}
+		})
+	}
+}
+
+func TestConfigLoadDuplicateTenant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "tenants:\n  - name: prod\n  - name: prod\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var c Config
+	if err := c.Load(path, false); err == nil || !strings.Contains(err.Error(), "duplicate tenant") {
+		t.Errorf("expected a duplicate tenant error, got: %v", err)
+	}
+}
+
+func TestManagedDomains(t *testing.T) {
+	client := newFakeAdminServiceClient()
+	for _, email := range []string{"stale@example.com", "other@other.example.com"} {
+		client.groups[email] = &admin.Group{Email: email}
+	}
+	as := &adminService{client: client, log: discardLogger(), managedDomains: []string{"example.com"}}
+
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+
+	if err := as.DeleteGroupsIfNecessary(nil); err != nil {
+		t.Fatalf("unexpected error deleting groups: %v", err)
+	}
+	if expected := []string{"DeleteGroup stale@example.com"}; !reflect.DeepEqual(expected, client.calls) {
+		t.Errorf("unexpected calls: expected %v, got %v", expected, client.calls)
+	}
+
+	err := as.CreateOrUpdateGroupIfNescessary(GoogleGroup{EmailId: "new@other.example.com"})
+	if err == nil || !strings.Contains(err.Error(), "outside the managed domains") {
+		t.Errorf("expected an error creating a group outside the managed domains, got: %v", err)
+	}
+
+	rootDir := t.TempDir()
+	content := "groups:\n  - email-id: ok@Example.com\n  - email-id: bad@other.example.com\n"
+	if err := ioutil.WriteFile(filepath.Join(rootDir, "groups.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var gc GroupsConfig
+	err = gc.Load(rootDir, GroupsLoadOptions{ManagedDomains: []string{"example.com"}}, &RestrictionsConfig{})
+	if err == nil || !strings.Contains(err.Error(), "bad@other.example.com") || strings.Contains(err.Error(), "ok@") {
+		t.Errorf("expected only the group outside the managed domains to be rejected, got: %v", err)
+	}
+}
+
+// TestPrintRoundTrip tests that reconciling the printed config against the
+// state it was printed from doesn't make any changes.
+func TestPrintRoundTrip(t *testing.T) {
+	r, ac, gc := newFakeReconciler()
+	custom := defaultSettings()
+	custom.AllowWebPosting = "false"
+	custom.WhoCanPostMessage = "ALL_MANAGERS_CAN_POST"
+	custom.MessageModerationLevel = "MODERATE_ALL_MESSAGES"
+	custom.WhoCanModerateContent = "OWNERS_ONLY"
+	custom.WhoCanJoin = ""
+	addFakeGroup(ac, gc, "custom@example.com", custom,
+		"z@example.com", MemberRole, "a@example.com", MemberRole,
+		"owner@example.com", OwnerRole, "manager@example.com", ManagerRole)
+	addFakeGroup(ac, gc, "plain@example.com", defaultSettings(), "owner@example.com", OwnerRole)
+	ac.groups["plain@example.com"].Description = "a plain group"
+
+	printed, err := r.liveGroupsYAML()
+	if err != nil {
+		t.Fatalf("unexpected error printing groups: %v", err)
+	}
+	var printedConfig GroupsConfig
+	if err := yaml.Unmarshal([]byte(printed), &printedConfig); err != nil {
+		t.Fatalf("unable to parse printed config: %v\n%s", err, printed)
+	}
+	if len(printedConfig.Groups) != 2 {
+		t.Fatalf("expected 2 printed groups, got:\n%s", printed)
+	}
+
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+
+	if err := r.ReconcileGroups(printedConfig.Groups); err != nil {
+		t.Fatalf("unexpected error reconciling printed config: %v", err)
+	}
+	if len(ac.calls) > 0 || len(gc.calls) > 0 {
+		t.Errorf("expected no changes reconciling printed config, got %v %v\n%s", ac.calls, gc.calls, printed)
+	}
+}
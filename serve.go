@@ -0,0 +1,263 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+// serveCommand registers the flags of the serve command, which keeps running
+// and reconciles the groups whenever the config changes.
+func serveCommand() func(configFilePath string) error {
+	listen := flag.String("listen", ":8080", "the address to serve the health, readiness and metrics endpoints on")
+	pollInterval := flag.Duration("poll-interval", time.Minute, "how often to reload the config")
+	resyncInterval := flag.Duration("resync-interval", time.Hour, "how often to reconcile even if the config did not change")
+	gitDir := flag.String("git-dir", "", "if set, the git checkout to pull with --ff-only before reloading the config")
+	confirmChanges := flag.Bool("confirm", false, "false by default means that we do not push anything to google groups")
+
+	return func(configFilePath string) error {
+		s := &server{
+			configFilePath: configFilePath,
+			confirmChanges: *confirmChanges,
+			pollInterval:   *pollInterval,
+			resyncInterval: *resyncInterval,
+			gitDir:         *gitDir,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		httpServer := &http.Server{Addr: *listen, Handler: s.handler()}
+		go func() {
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.WithError(err).Fatalf("unable to serve on %s", *listen)
+			}
+		}()
+		logger.Infof("serve: listening on %s, polling every %v, resyncing every %v", *listen, *pollInterval, *resyncInterval)
+
+		ticker := time.NewTicker(*pollInterval)
+		defer ticker.Stop()
+		for {
+			s.poll(time.Now())
+
+			select {
+			case <-ctx.Done():
+				logger.Info("serve: shutting down")
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				return httpServer.Shutdown(shutdownCtx)
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// server reconciles the groups each time their config changes, and reports
+// on its progress through HTTP endpoints.
+type server struct {
+	configFilePath string
+	confirmChanges bool
+	// pollInterval also bounds how long pulling the config may take, so a
+	// hung pull doesn't block the following polls.
+	pollInterval   time.Duration
+	resyncInterval time.Duration
+	gitDir         string
+
+	mu sync.Mutex
+	// lastPoll is when the config was last polled, successfully or not.
+	lastPoll time.Time
+	// lastHash is the hash of the config last reconciled successfully.
+	lastHash string
+	// lastReconcile is when the config was last reconciled successfully.
+	lastReconcile time.Time
+	// lastErr is the error of the last poll, if any.
+	lastErr error
+}
+
+// poll pulls and reloads the config, and reconciles the groups if the config
+// changed since the last successful reconciliation or if it is time to resync.
+func (s *server) poll(now time.Time) {
+	err := s.sync(now)
+	if err != nil {
+		logger.WithError(err).Error("serve: unable to sync")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastPoll = now
+	s.lastErr = err
+}
+
+func (s *server) sync(now time.Time) error {
+	if s.gitDir != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), s.pollInterval)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "git", "-C", s.gitDir, "pull", "--ff-only")
+		// Fail rather than wait for credentials nobody will type.
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("unable to pull %s: %v: %s", s.gitDir, err, out)
+		}
+	}
+
+	var c Config
+	if err := c.Load(s.configFilePath, s.confirmChanges); err != nil {
+		return err
+	}
+	config = c
+
+	groups := map[string]*GroupsConfig{}
+	for i := range config.Tenants {
+		t := &config.Tenants[i]
+		gc, err := loadTenantGroups(t)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", t.Name, err)
+		}
+		groups[t.Name] = gc
+	}
+
+	hash, err := configHash(&config, groups)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	changed := hash != s.lastHash
+	due := now.Sub(s.lastReconcile) >= s.resyncInterval
+	s.mu.Unlock()
+	if !changed && !due {
+		return nil
+	}
+	logger.WithFields(logrus.Fields{"hash": hash, "changed": changed, "resyncDue": due}).Info("serve: reconciling config")
+
+	mode := PlanMode
+	if config.ConfirmChanges {
+		mode = ApplyMode
+	}
+	err = forEachTenant(mode, func(t *Tenant, r *Reconciler) (string, error) {
+		g := groups[t.Name]
+		if err := snapshotBeforeApply(t, r, fullApplyPlan(g.Groups)); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d groups", len(g.Groups)), r.ReconcileGroups(g.Groups)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastHash = hash
+	s.lastReconcile = now
+	return nil
+}
+
+// configHash returns a hash of the parts of the config that affect the
+// reconciliation, so that formatting or comment changes are ignored.
+func configHash(c *Config, groups map[string]*GroupsConfig) (string, error) {
+	// The expiry dates are not marshalled along with the groups.
+	expires := map[string]map[string]map[string]time.Time{}
+	for tenant, gc := range groups {
+		if gc == nil {
+			continue
+		}
+		for _, g := range gc.Groups {
+			if len(g.Expires) == 0 {
+				continue
+			}
+			if expires[tenant] == nil {
+				expires[tenant] = map[string]map[string]time.Time{}
+			}
+			expires[tenant][g.EmailId] = g.Expires
+		}
+	}
+	content, err := json.Marshal(struct {
+		Config  *Config
+		Groups  map[string]*GroupsConfig
+		Expires map[string]map[string]map[string]time.Time
+	}{c, groups, expires})
+	if err != nil {
+		return "", fmt.Errorf("unable to hash config: %w", err)
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// serveStatus is the body of the responses of the health and readiness
+// endpoints.
+type serveStatus struct {
+	LastPoll      time.Time `json:"lastPoll,omitempty"`
+	LastReconcile time.Time `json:"lastReconcile,omitempty"`
+	ConfigHash    string    `json:"configHash,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
+
+func (s *server) status() serveStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := serveStatus{
+		LastPoll:      s.lastPoll,
+		LastReconcile: s.lastReconcile,
+		ConfigHash:    s.lastHash,
+	}
+	if s.lastErr != nil {
+		status.Error = s.lastErr.Error()
+	}
+	return status
+}
+
+// handler serves /healthz, which succeeds as long as the server is running,
+// /readyz, which succeeds once the groups were reconciled and the last poll
+// succeeded, and /metrics.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w, http.StatusOK, s.status())
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		status := s.status()
+		code := http.StatusOK
+		if status.LastReconcile.IsZero() || status.Error != "" {
+			code = http.StatusServiceUnavailable
+		}
+		writeStatus(w, code, status)
+	})
+	mux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+	return mux
+}
+
+func writeStatus(w http.ResponseWriter, code int, status serveStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(status)
+}
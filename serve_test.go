@@ -0,0 +1,80 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConfigHash(t *testing.T) {
+	c := &Config{Tenants: []Tenant{{Name: "default", BotID: "bot@example.com"}}}
+	groups := func(members ...string) map[string]*GroupsConfig {
+		return map[string]*GroupsConfig{
+			"default": {Groups: []GoogleGroup{{EmailId: "team@example.com", Members: members}}},
+		}
+	}
+
+	base, err := configHash(c, groups("a@example.com"))
+	if err != nil {
+		t.Fatalf("unexpected error hashing config: %v", err)
+	}
+	same, _ := configHash(c, groups("a@example.com"))
+	if base != same {
+		t.Errorf("expected the same config to have the same hash")
+	}
+	changed, _ := configHash(c, groups("a@example.com", "b@example.com"))
+	if base == changed {
+		t.Errorf("expected a membership change to change the hash")
+	}
+
+	expiring := groups("a@example.com")
+	expiring["default"].Groups[0].Expires = map[string]time.Time{expiryKey(MemberRole, "a@example.com"): time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)}
+	expiringHash, _ := configHash(c, expiring)
+	if base == expiringHash {
+		t.Errorf("expected an expiry date to change the hash")
+	}
+	expiring["default"].Groups[0].Expires[expiryKey(MemberRole, "a@example.com")] = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	if moved, _ := configHash(c, expiring); moved == expiringHash {
+		t.Errorf("expected moving an expiry date to change the hash")
+	}
+}
+
+func TestServeReadiness(t *testing.T) {
+	s := &server{}
+	check := func(path string, expected int) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != expected {
+			t.Errorf("unexpected status for %s: expected %d, got %d: %s", path, expected, w.Code, w.Body)
+		}
+	}
+
+	check("/healthz", http.StatusOK)
+	check("/readyz", http.StatusServiceUnavailable)
+
+	s.lastReconcile = time.Now()
+	check("/readyz", http.StatusOK)
+
+	s.lastErr = errors.New("unable to pull")
+	check("/healthz", http.StatusOK)
+	check("/readyz", http.StatusServiceUnavailable)
+}
@@ -20,11 +20,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
 	admin "google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/googleapi"
 	groupssettings "google.golang.org/api/groupssettings/v1"
@@ -43,7 +45,8 @@ const (
 type AdminService interface {
 	AddOrUpdateGroupMembers(group GoogleGroup, role string, members []string) error
 	CreateOrUpdateGroupIfNescessary(group GoogleGroup) error
-	DeleteGroupsIfNecessary() error
+	DeleteGroupsIfNecessary(groups []GoogleGroup) error
+	DeleteGroups(emails []string) error
 	RemoveOwnerOrManagersFromGroup(group GoogleGroup, members []string) error
 	RemoveMembersFromGroup(group GoogleGroup, members []string) error
 	// ListGroup here is a proxy to the ListGroups method of the underlying
@@ -63,40 +66,88 @@ type GroupService interface {
 	Get(groupUniqueID string) (*groupssettings.Groups, error)
 }
 
-func NewAdminService(ctx context.Context, clientOption option.ClientOption) (AdminService, error) {
-	client, err := NewAdminServiceClient(ctx, clientOption)
+// NewAdminService returns an AdminService for the groups of the tenant t,
+// listed per domain if it has several. Only groups in its managed domains
+// are created or deleted, unless it has none. The changes are appended to
+// audit if not nil.
+func NewAdminService(ctx context.Context, t *Tenant, log *logrus.Entry, audit *tenantAudit, clientOption option.ClientOption) (AdminService, error) {
+	client, err := NewAdminServiceClient(ctx, t.CustomerID, t.listDomains(), t.APIEndpoint, clientOption)
 	if err != nil {
 		return nil, err
 	}
+	client = &instrumentedAdminServiceClient{client: client}
+	if audit != nil {
+		client = &auditedAdminServiceClient{AdminServiceClient: client, audit: audit}
+	}
 
-	return &adminService{client: client}, nil
+	as := &adminService{
+		client:         client,
+		log:            log,
+		tenant:         t.Name,
+		managedDomains: t.ManagedDomains,
+	}
+	if config.SoftDelete != nil {
+		settings, err := newGroupServiceClient(ctx, t.APIEndpoint, audit, clientOption)
+		if err != nil {
+			return nil, err
+		}
+		as.softDelete = &softDeleter{
+			config:   *config.SoftDelete,
+			tenant:   t.Name,
+			client:   client,
+			settings: settings,
+			log:      log,
+			now:      time.Now,
+		}
+	}
+	return as, nil
 }
 
-func NewGroupService(ctx context.Context, clientOption option.ClientOption) (GroupService, error) {
-	client, err := NewGroupServiceClient(ctx, clientOption)
+func NewGroupService(ctx context.Context, t *Tenant, log *logrus.Entry, audit *tenantAudit, clientOption option.ClientOption) (GroupService, error) {
+	client, err := newGroupServiceClient(ctx, t.APIEndpoint, audit, clientOption)
 	if err != nil {
 		return nil, err
 	}
 
-	return &groupService{client: client}, nil
+	return &groupService{client: client, log: log, tenant: t.Name}, nil
+}
+
+// newGroupServiceClient returns an instrumented GroupServiceClient, auditing
+// its changes to audit if not nil.
+func newGroupServiceClient(ctx context.Context, endpoint string, audit *tenantAudit, clientOption option.ClientOption) (GroupServiceClient, error) {
+	client, err := NewGroupServiceClient(ctx, endpoint, clientOption)
+	if err != nil {
+		return nil, err
+	}
+	client = &instrumentedGroupServiceClient{client: client}
+	if audit != nil {
+		client = &auditedGroupServiceClient{GroupServiceClient: client, audit: audit}
+	}
+	return client, nil
 }
 
 type adminService struct {
 	client AdminServiceClient
+	log    *logrus.Entry
+	// tenant is the name of the tenant the changes are counted against.
+	tenant         string
+	managedDomains []string
+	// softDelete, if not nil, makes groups pending deletion before they
+	// are deleted.
+	softDelete *softDeleter
 }
 
 // AddOrUpdateGroupMembers first lists all members that are part of group. Based on this list and the
 // members, it will update the member in the group (if needed) or if the member is not found in the
 // list, it will create the member.
 func (as *adminService) AddOrUpdateGroupMembers(group GoogleGroup, role string, members []string) error {
-	if *verbose {
-		log.Printf("adminService.AddOrUpdateGroupMembers %s %s %v", group.EmailId, role, members)
-	}
+	log := as.log.WithFields(logrus.Fields{groupField: group.EmailId, roleField: role})
+	log.Debugf("adding or updating members %v", members)
 
 	l, err := as.client.ListMembers(group.EmailId)
 	if err != nil {
 		if apierr, ok := err.(*googleapi.Error); ok && apierr.Code == http.StatusNotFound {
-			log.Printf("skipping adding members to group %q as it has not yet been created\n", group.EmailId)
+			log.Info("skipping adding members as the group has not yet been created")
 			return nil
 		}
 		return fmt.Errorf("unable to retrieve members in group %q: %w", group.EmailId, err)
@@ -104,10 +155,15 @@ func (as *adminService) AddOrUpdateGroupMembers(group GoogleGroup, role string,
 
 	// aggregate the errors that occured and return them together in the end.
 	var errs []error
+	ignored := ignoredMembers(group)
 	for _, memberEmailId := range members {
+		if ignored(memberEmailId) {
+			log.WithField(memberField, memberEmailId).Info("ignored member matching ignore-members")
+			continue
+		}
 		var member *admin.Member
 		for _, m := range l.Members {
-			if m.Email == memberEmailId {
+			if strings.EqualFold(m.Email, memberEmailId) {
 				member = m
 				break
 			}
@@ -117,15 +173,18 @@ func (as *adminService) AddOrUpdateGroupMembers(group GoogleGroup, role string,
 			// update if necessary
 			if member.Role != role {
 				member.Role = role
+				log := changeLog(log, UpdateMemberChange, group.EmailId).WithField(memberField, memberEmailId)
 				if config.ConfirmChanges {
 					_, err := as.client.UpdateMember(group.EmailId, member.Email, member)
 					if err != nil {
 						errs = append(errs, fmt.Errorf("unable to update %s in %q as %s : %w", memberEmailId, group.EmailId, role, err))
 						continue
 					}
-					log.Printf("Updated %s to %q as a %s\n", memberEmailId, group.EmailId, role)
+					recordChange(as.tenant, UpdateMemberChange, true)
+					log.Info("updated member")
 				} else {
-					log.Printf("dry-run: would update %s to %q as %s\n", memberEmailId, group.EmailId, role)
+					recordChange(as.tenant, UpdateMemberChange, false)
+					log.Info("would update member")
 				}
 			}
 			continue
@@ -136,15 +195,18 @@ func (as *adminService) AddOrUpdateGroupMembers(group GoogleGroup, role string,
 		}
 
 		// We did not find the person in the google group, so we add them
+		log := changeLog(log, AddMemberChange, group.EmailId).WithField(memberField, memberEmailId)
 		if config.ConfirmChanges {
 			_, err := as.client.InsertMember(group.EmailId, member)
 			if err != nil {
 				errs = append(errs, fmt.Errorf("unable to add %s to %q as %s : %w", memberEmailId, group.EmailId, role, err))
 				continue
 			}
-			log.Printf("Added %s to %q as a %s\n", memberEmailId, group.EmailId, role)
+			recordChange(as.tenant, AddMemberChange, true)
+			log.Info("added member")
 		} else {
-			log.Printf("dry-run: would add %s to %q as %s\n", memberEmailId, group.EmailId, role)
+			recordChange(as.tenant, AddMemberChange, false)
+			log.Info("would add member")
 		}
 	}
 
@@ -155,17 +217,26 @@ func (as *adminService) AddOrUpdateGroupMembers(group GoogleGroup, role string,
 // does not already exist. If it exists, it will update the group if needed to match the
 // provided group.
 func (as *adminService) CreateOrUpdateGroupIfNescessary(group GoogleGroup) error {
-	if *verbose {
-		log.Printf("adminService.CreateOrUpdateGroupIfNecessary %s", group.EmailId)
+	as.log.WithField(groupField, group.EmailId).Debug("creating or updating group")
+
+	if as.softDelete != nil {
+		if err := as.softDelete.Restore(group.EmailId); err != nil {
+			return err
+		}
 	}
 
 	grp, err := as.client.GetGroup(group.EmailId)
 	if err != nil {
 		if apierr, ok := err.(*googleapi.Error); ok && apierr.Code == http.StatusNotFound {
+			if !inManagedDomains(group.EmailId, as.managedDomains) {
+				return fmt.Errorf("refusing to create group %q outside the managed domains %v", group.EmailId, as.managedDomains)
+			}
+			log := changeLog(as.log, CreateGroupChange, group.EmailId)
 			if !config.ConfirmChanges {
-				log.Printf("dry-run: would create group %q\n", group.EmailId)
+				recordChange(as.tenant, CreateGroupChange, false)
+				log.Info("would create group")
 			} else {
-				log.Printf("Trying to create group: %q\n", group.EmailId)
+				log.Debug("creating group")
 				g := admin.Group{
 					Email: group.EmailId,
 				}
@@ -175,11 +246,12 @@ func (as *adminService) CreateOrUpdateGroupIfNescessary(group GoogleGroup) error
 				if group.Description != "" {
 					g.Description = group.Description
 				}
-				g4, err := as.client.InsertGroup(&g)
+				_, err := as.client.InsertGroup(&g)
 				if err != nil {
 					return fmt.Errorf("unable to add new group %q: %w", group.EmailId, err)
 				}
-				log.Printf("> Successfully created group %s\n", g4.Email)
+				recordChange(as.tenant, CreateGroupChange, true)
+				log.Info("created group")
 			}
 		} else {
 			return fmt.Errorf("unable to fetch group %q: %w", group.EmailId, err)
@@ -187,10 +259,12 @@ func (as *adminService) CreateOrUpdateGroupIfNescessary(group GoogleGroup) error
 	} else {
 		if group.Name != "" && grp.Name != group.Name ||
 			group.Description != "" && grp.Description != group.Description {
+			log := changeLog(as.log, UpdateGroupChange, group.EmailId)
 			if !config.ConfirmChanges {
-				log.Printf("dry-run: would update group name/description %q\n", group.EmailId)
+				recordChange(as.tenant, UpdateGroupChange, false)
+				log.Info("would update group name and description")
 			} else {
-				log.Printf("Trying to update group: %q\n", group.EmailId)
+				log.Debug("updating group")
 				g := admin.Group{
 					Email: group.EmailId,
 				}
@@ -200,11 +274,12 @@ func (as *adminService) CreateOrUpdateGroupIfNescessary(group GoogleGroup) error
 				if group.Description != "" {
 					g.Description = group.Description
 				}
-				g4, err := as.client.UpdateGroup(group.EmailId, &g)
+				_, err := as.client.UpdateGroup(group.EmailId, &g)
 				if err != nil {
 					return fmt.Errorf("unable to update group %q: %w", group.EmailId, err)
 				}
-				log.Printf("> Successfully updated group %s\n", g4.Email)
+				recordChange(as.tenant, UpdateGroupChange, true)
+				log.Info("updated group name and description")
 			}
 		}
 	}
@@ -213,8 +288,8 @@ func (as *adminService) CreateOrUpdateGroupIfNescessary(group GoogleGroup) error
 
 // DeleteGroupsIfNecessary checks against the groups config provided by the user. It
 // first lists all existing groups, if a group in this list does not appear in the
-// provided group config, it will delete this group to match the desired state.
-func (as *adminService) DeleteGroupsIfNecessary() error {
+// provided groups, it will delete this group to match the desired state.
+func (as *adminService) DeleteGroupsIfNecessary(groups []GoogleGroup) error {
 	g, err := as.client.ListGroups()
 	if err != nil {
 		return fmt.Errorf("unable to retrieve users in domain: %w", err)
@@ -224,7 +299,7 @@ func (as *adminService) DeleteGroupsIfNecessary() error {
 	var errs []error
 	for _, g := range g.Groups {
 		found := false
-		for _, g2 := range groupsConfig.Groups {
+		for _, g2 := range groups {
 			if g2.EmailId == g.Email {
 				found = true
 				break
@@ -235,36 +310,73 @@ func (as *adminService) DeleteGroupsIfNecessary() error {
 		}
 
 		// We did not find the group in our groups.xml, so delete the group
-		if config.ConfirmChanges {
-			if *verbose {
-				log.Printf("deleting group %s", g.Email)
-			}
-			err := as.client.DeleteGroup(g.Email)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("unable to remove group %s : %w", g.Email, err))
+		if err := as.deleteGroup(g.Email); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// DeleteGroups deletes the groups with the given emails, skipping the ones
+// that don't exist.
+func (as *adminService) DeleteGroups(emails []string) error {
+	// aggregate the errors that occured and return them together in the end.
+	var errs []error
+	for _, email := range emails {
+		if _, err := as.client.GetGroup(email); err != nil {
+			if apierr, ok := err.(*googleapi.Error); ok && apierr.Code == http.StatusNotFound {
+				as.log.WithField(groupField, email).Debug("skipping removing group as it does not exist")
 				continue
 			}
-			log.Printf("Removing group %s\n", g.Email)
-		} else {
-			log.Printf("dry-run: would remove group %s\n", g.Email)
+			errs = append(errs, fmt.Errorf("unable to fetch group %q: %w", email, err))
+			continue
+		}
+		if err := as.deleteGroup(email); err != nil {
+			errs = append(errs, err)
 		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
 
+// deleteGroup deletes the group with the given email, unless it is not in
+// one of the managed domains.
+func (as *adminService) deleteGroup(email string) error {
+	// Groups of domains that are not managed here are left alone, even
+	// if they were listed.
+	if !inManagedDomains(email, as.managedDomains) {
+		as.log.WithField(groupField, email).Debug("skipping group outside the managed domains")
+		return nil
+	}
+	if as.softDelete != nil {
+		return as.softDelete.Delete(email)
 	}
 
-	return utilerrors.NewAggregate(errs)
+	log := changeLog(as.log, DeleteGroupChange, email)
+	if !config.ConfirmChanges {
+		recordChange(as.tenant, DeleteGroupChange, false)
+		log.Info("would remove group")
+		return nil
+	}
+	log.Debug("deleting group")
+	if err := as.client.DeleteGroup(email); err != nil {
+		return fmt.Errorf("unable to remove group %s : %w", email, err)
+	}
+	recordChange(as.tenant, DeleteGroupChange, true)
+	log.Info("removed group")
+	return nil
 }
 
 // RemoveOwnerOrManagersFromGroup lists members of the group and checks against the list of members
 // passed. If a member from the retrieved list of members does not exist in the passed list of members,
 // this member is removed - provided this member had a OWNER/MANAGER role.
 func (as *adminService) RemoveOwnerOrManagersFromGroup(group GoogleGroup, members []string) error {
-	if *verbose {
-		log.Printf("adminService.RemoveOwnerOrManagersGroup %s %v", group.EmailId, members)
-	}
+	log := as.log.WithField(groupField, group.EmailId)
+	log.Debugf("removing owners or managers not in %v", members)
 	l, err := as.client.ListMembers(group.EmailId)
 	if err != nil {
 		if apierr, ok := err.(*googleapi.Error); ok && apierr.Code == http.StatusNotFound {
-			log.Printf("skipping removing members group %q as group has not yet been created\n", group.EmailId)
+			log.Info("skipping removing members as the group has not yet been created")
 			return nil
 		}
 		return fmt.Errorf("unable to retrieve members in group %q: %w", group.EmailId, err)
@@ -272,10 +384,11 @@ func (as *adminService) RemoveOwnerOrManagersFromGroup(group GoogleGroup, member
 
 	// aggregate the errors that occured and return them together in the end.
 	var errs []error
+	ignored := ignoredMembers(group)
 	for _, m := range l.Members {
 		found := false
 		for _, m2 := range members {
-			if m2 == m.Email {
+			if strings.EqualFold(m2, m.Email) {
 				found = true
 				break
 			}
@@ -288,16 +401,23 @@ func (as *adminService) RemoveOwnerOrManagersFromGroup(group GoogleGroup, member
 		if found || m.Role == MemberRole {
 			continue
 		}
+		if ignored(m.Email) {
+			log.WithFields(logrus.Fields{memberField: m.Email, roleField: m.Role}).Info("ignored member matching ignore-members")
+			continue
+		}
 		// a person was deleted from a group, let's remove them
+		log := changeLog(log, RemoveMemberChange, group.EmailId).WithFields(logrus.Fields{memberField: m.Email, roleField: m.Role})
 		if config.ConfirmChanges {
 			err := as.client.DeleteMember(group.EmailId, m.Id)
 			if err != nil {
 				errs = append(errs, fmt.Errorf("unable to remove %s from %q as OWNER or MANAGER : %w", m.Email, group.EmailId, err))
 				continue
 			}
-			log.Printf("Removing %s from %q as OWNER or MANAGER\n", m.Email, group.EmailId)
+			recordChange(as.tenant, RemoveMemberChange, true)
+			log.Info("removed member")
 		} else {
-			log.Printf("dry-run: would remove %s from %q as OWNER or MANAGER\n", m.Email, group.EmailId)
+			recordChange(as.tenant, RemoveMemberChange, false)
+			log.Info("would remove member")
 		}
 	}
 
@@ -309,13 +429,12 @@ func (as *adminService) RemoveOwnerOrManagersFromGroup(group GoogleGroup, member
 // member is removed. Unlike RemoveOwnerOrManagersFromGroup, RemoveMembersFromGroup will remove the
 // member regardless of the role that the member held.
 func (as *adminService) RemoveMembersFromGroup(group GoogleGroup, members []string) error {
-	if *verbose {
-		log.Printf("adminService.RemoveMembersFromGroup %s %v", group.EmailId, members)
-	}
+	log := as.log.WithField(groupField, group.EmailId)
+	log.Debugf("removing members not in %v", members)
 	l, err := as.client.ListMembers(group.EmailId)
 	if err != nil {
 		if apierr, ok := err.(*googleapi.Error); ok && apierr.Code == http.StatusNotFound {
-			log.Printf("skipping removing members group %q as group has not yet been created\n", group.EmailId)
+			log.Info("skipping removing members as the group has not yet been created")
 			return nil
 		}
 		return fmt.Errorf("unable to retrieve members in group %q: %w", group.EmailId, err)
@@ -323,10 +442,11 @@ func (as *adminService) RemoveMembersFromGroup(group GoogleGroup, members []stri
 
 	// aggregate the errors that occured and return them together in the end.
 	var errs []error
+	ignored := ignoredMembers(group)
 	for _, m := range l.Members {
 		found := false
 		for _, m2 := range members {
-			if m2 == m.Email {
+			if strings.EqualFold(m2, m.Email) {
 				found = true
 				break
 			}
@@ -334,17 +454,24 @@ func (as *adminService) RemoveMembersFromGroup(group GoogleGroup, members []stri
 		if found {
 			continue
 		}
+		if ignored(m.Email) {
+			log.WithFields(logrus.Fields{memberField: m.Email, roleField: m.Role}).Info("ignored member matching ignore-members")
+			continue
+		}
 
 		// a person was deleted from a group, let's remove them
+		log := changeLog(log, RemoveMemberChange, group.EmailId).WithFields(logrus.Fields{memberField: m.Email, roleField: m.Role})
 		if config.ConfirmChanges {
 			err := as.client.DeleteMember(group.EmailId, m.Id)
 			if err != nil {
 				errs = append(errs, fmt.Errorf("unable to remove %s from %q as a %s : %w", m.Email, group.EmailId, m.Role, err))
 				continue
 			}
-			log.Printf("Removing %s from %q as a %s\n", m.Email, group.EmailId, m.Role)
+			recordChange(as.tenant, RemoveMemberChange, true)
+			log.Info("removed member")
 		} else {
-			log.Printf("dry-run: would remove %s from %q as a %s\n", m.Email, group.EmailId, m.Role)
+			recordChange(as.tenant, RemoveMemberChange, false)
+			log.Info("would remove member")
 		}
 	}
 
@@ -365,18 +492,89 @@ var _ AdminService = (*adminService)(nil)
 
 type groupService struct {
 	client GroupServiceClient
+	log    *logrus.Entry
+	// tenant is the same as adminService.tenant, for the settings changes.
+	tenant string
+}
+
+// groupSetting describes a field of groupssettings.Groups that is managed
+// through the Settings of a GoogleGroup.
+type groupSetting struct {
+	// Name is the key of the setting in GoogleGroup.Settings.
+	Name string
+	// Default is the value the setting is reconciled to when it is not
+	// specified in the config. If empty, the live value is left untouched.
+	Default string
+	// Initial is the value Google gives the setting in new groups. It is
+	// only used when Default is empty, to leave out unsurprising values
+	// when the live settings are turned into config.
+	Initial string
+	// field returns a pointer to the setting in g.
+	field func(g *groupssettings.Groups) *string
+}
+
+// groupSettings lists every setting UpdateGroupSettings reconciles.
+var groupSettings = []groupSetting{
+	{"AllowExternalMembers", "true", "", func(g *groupssettings.Groups) *string { return &g.AllowExternalMembers }},
+	{"AllowWebPosting", "", "true", func(g *groupssettings.Groups) *string { return &g.AllowWebPosting }},
+	{"WhoCanJoin", "INVITED_CAN_JOIN", "", func(g *groupssettings.Groups) *string { return &g.WhoCanJoin }},
+	{"WhoCanViewMembership", "ALL_MANAGERS_CAN_VIEW", "", func(g *groupssettings.Groups) *string { return &g.WhoCanViewMembership }},
+	{"WhoCanViewGroup", "ALL_MEMBERS_CAN_VIEW", "", func(g *groupssettings.Groups) *string { return &g.WhoCanViewGroup }},
+	{"WhoCanDiscoverGroup", "ALL_IN_DOMAIN_CAN_DISCOVER", "", func(g *groupssettings.Groups) *string { return &g.WhoCanDiscoverGroup }},
+	{"WhoCanModerateMembers", "OWNERS_AND_MANAGERS", "", func(g *groupssettings.Groups) *string { return &g.WhoCanModerateMembers }},
+	{"WhoCanModerateContent", "OWNERS_AND_MANAGERS", "", func(g *groupssettings.Groups) *string { return &g.WhoCanModerateContent }},
+	{"WhoCanPostMessage", "ALL_MEMBERS_CAN_POST", "", func(g *groupssettings.Groups) *string { return &g.WhoCanPostMessage }},
+	{"MessageModerationLevel", "MODERATE_NONE", "", func(g *groupssettings.Groups) *string { return &g.MessageModerationLevel }},
+	{"MembersCanPostAsTheGroup", "false", "", func(g *groupssettings.Groups) *string { return &g.MembersCanPostAsTheGroup }},
+}
+
+// lookupGroupSetting returns the groupSetting with the given name.
+func lookupGroupSetting(name string) (groupSetting, bool) {
+	for _, setting := range groupSettings {
+		if setting.Name == name {
+			return setting, true
+		}
+	}
+	return groupSetting{}, false
+}
+
+// nonDefaultSettings returns the settings of g that differ from what they
+// would be reconciled to if they were not specified in the config. Settings
+// without a Default are left out when unset or equal to their Initial value,
+// as the reconciler leaves them untouched when they are not specified.
+func nonDefaultSettings(g *groupssettings.Groups) map[string]string {
+	settings := map[string]string{}
+	for _, setting := range groupSettings {
+		value := *setting.field(g)
+		if value == setting.Default || setting.Default == "" && value == setting.Initial {
+			continue
+		}
+		settings[setting.Name] = value
+	}
+	return settings
+}
+
+// allSettings returns every setting of g that has a value, whether it is
+// the default or not.
+func allSettings(g *groupssettings.Groups) map[string]string {
+	settings := map[string]string{}
+	for _, setting := range groupSettings {
+		if value := *setting.field(g); value != "" {
+			settings[setting.Name] = value
+		}
+	}
+	return settings
 }
 
 // UpdateGroupSettings updates the groupsettings.Groups corresponding to the
 // passed group based on what the current state of the groupsetting.Groups is.
 func (gs *groupService) UpdateGroupSettings(group GoogleGroup) error {
-	if *verbose {
-		log.Printf("groupService.UpdateGroupSettings %s", group.EmailId)
-	}
+	log := gs.log.WithField(groupField, group.EmailId)
+	log.Debug("updating group settings")
 	g2, err := gs.client.Get(group.EmailId)
 	if err != nil {
 		if apierr, ok := err.(*googleapi.Error); ok && apierr.Code == http.StatusNotFound {
-			log.Printf("skipping updating group settings as group %q has not yet been created\n", group.EmailId)
+			log.Info("skipping updating group settings as the group has not yet been created")
 			return nil
 		}
 		return fmt.Errorf("unable to retrieve group info for group %q: %w", group.EmailId, err)
@@ -393,60 +591,32 @@ func (gs *groupService) UpdateGroupSettings(group GoogleGroup) error {
 	deepCopySettings(&haveSettings, &wantSettings)
 
 	// This sets safe/sane defaults
-	wantSettings.AllowExternalMembers = "true"
-	wantSettings.WhoCanJoin = "INVITED_CAN_JOIN"
-	wantSettings.WhoCanViewMembership = "ALL_MANAGERS_CAN_VIEW"
-	wantSettings.WhoCanViewGroup = "ALL_MEMBERS_CAN_VIEW"
-	wantSettings.WhoCanDiscoverGroup = "ALL_IN_DOMAIN_CAN_DISCOVER"
-	wantSettings.WhoCanModerateMembers = "OWNERS_AND_MANAGERS"
-	wantSettings.WhoCanModerateContent = "OWNERS_AND_MANAGERS"
-	wantSettings.WhoCanPostMessage = "ALL_MEMBERS_CAN_POST"
-	wantSettings.MessageModerationLevel = "MODERATE_NONE"
-	wantSettings.MembersCanPostAsTheGroup = "false"
+	for _, setting := range groupSettings {
+		if setting.Default != "" {
+			*setting.field(&wantSettings) = setting.Default
+		}
+	}
 
 	for key, value := range group.Settings {
-		switch key {
-		case "AllowExternalMembers":
-			wantSettings.AllowExternalMembers = value
-		case "AllowWebPosting":
-			wantSettings.AllowWebPosting = value
-		case "WhoCanJoin":
-			wantSettings.WhoCanJoin = value
-		case "WhoCanViewMembership":
-			wantSettings.WhoCanViewMembership = value
-		case "WhoCanViewGroup":
-			wantSettings.WhoCanViewGroup = value
-		case "WhoCanDiscoverGroup":
-			wantSettings.WhoCanDiscoverGroup = value
-		case "WhoCanModerateMembers":
-			wantSettings.WhoCanModerateMembers = value
-		case "WhoCanPostMessage":
-			wantSettings.WhoCanPostMessage = value
-		case "MessageModerationLevel":
-			wantSettings.MessageModerationLevel = value
-		case "MembersCanPostAsTheGroup":
-			wantSettings.MembersCanPostAsTheGroup = value
+		if setting, ok := lookupGroupSetting(key); ok {
+			*setting.field(&wantSettings) = value
 		}
 	}
 
 	diff := cmp.Diff(haveSettings, wantSettings)
 
 	if !reflect.DeepEqual(&haveSettings, &wantSettings) {
+		log := changeLog(gs.log, UpdateSettingsChange, group.EmailId).WithField("diff", diff)
 		if config.ConfirmChanges {
-			log.Printf("The following changes will been made for %s:\n%+s",
-				group.EmailId,
-				diff,
-			)
 			_, err := gs.client.Patch(group.EmailId, &wantSettings)
 			if err != nil {
 				return fmt.Errorf("unable to update group info for group %q: %w", group.EmailId, err)
 			}
-			log.Printf("> Successfully updated group settings for %q to allow external members and other security settings\n", group.EmailId)
+			recordChange(gs.tenant, UpdateSettingsChange, true)
+			log.Info("updated group settings")
 		} else {
-			log.Printf("The following changes would have been made for %s:\n%+s",
-				group.EmailId,
-				diff,
-			)
+			recordChange(gs.tenant, UpdateSettingsChange, false)
+			log.Info("would update group settings")
 		}
 	}
 
@@ -0,0 +1,186 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iamcredentials/v1"
+	"google.golang.org/api/option"
+)
+
+// jwtBearerGrantType is the OAuth 2.0 grant type used to exchange a signed
+// JWT assertion for an access token.
+const jwtBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+// JWTSigner signs JWT claim sets on behalf of a service account.
+type JWTSigner interface {
+	// SignJWT returns the compact serialization of a JWT signed by
+	// serviceAccount with the given JSON encoded claims as payload.
+	SignJWT(ctx context.Context, serviceAccount, payload string) (string, error)
+}
+
+// NewIAMSigner returns a JWTSigner that signs through the signJwt method of
+// the IAM Credentials API, authenticated with the Application Default
+// Credentials. The ambient identity needs the Service Account Token Creator
+// role on the signing service account.
+func NewIAMSigner(ctx context.Context) (JWTSigner, error) {
+	ts, err := google.DefaultTokenSource(ctx, cloudPlatformScope)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find default credentials: %w", err)
+	}
+	service, err := iamcredentials.NewService(ctx, option.WithTokenSource(ts))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create iamcredentials client: %w", err)
+	}
+	return &iamSigner{service: service.Projects.ServiceAccounts}, nil
+}
+
+type iamSigner struct {
+	service *iamcredentials.ProjectsServiceAccountsService
+}
+
+func (s *iamSigner) SignJWT(ctx context.Context, serviceAccount, payload string) (string, error) {
+	name := "projects/-/serviceAccounts/" + serviceAccount
+	resp, err := s.service.SignJwt(name, &iamcredentials.SignJwtRequest{Payload: payload}).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to sign JWT as %s: %w", serviceAccount, err)
+	}
+	return resp.SignedJwt, nil
+}
+
+var _ JWTSigner = (*iamSigner)(nil)
+
+// signJWTCredentials authenticates with domain-wide delegation without a
+// service account key: the delegated JWT is signed by the signer and then
+// exchanged for an access token at tokenURL.
+type signJWTCredentials struct {
+	serviceAccount string
+	tokenURL       string
+	newSigner      func(ctx context.Context) (JWTSigner, error)
+}
+
+func (sc *signJWTCredentials) Client(ctx context.Context, subject string, scopes ...string) (*http.Client, error) {
+	signer, err := sc.newSigner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := &signJWTTokenSource{
+		ctx:            ctx,
+		signer:         signer,
+		serviceAccount: sc.serviceAccount,
+		subject:        subject,
+		scopes:         scopes,
+		tokenURL:       sc.tokenURL,
+	}
+	return oauth2.NewClient(ctx, oauth2.ReuseTokenSource(nil, ts)), nil
+}
+
+var _ CredentialProvider = (*signJWTCredentials)(nil)
+
+// signJWTTokenSource mints access tokens for subject by having signer sign a
+// JWT assertion issued by serviceAccount.
+type signJWTTokenSource struct {
+	ctx            context.Context
+	signer         JWTSigner
+	serviceAccount string
+	subject        string
+	scopes         []string
+	tokenURL       string
+}
+
+// jwtClaims is the claim set of a JWT used in the JWT bearer flow.
+type jwtClaims struct {
+	Iss   string `json:"iss"`
+	Sub   string `json:"sub,omitempty"`
+	Scope string `json:"scope"`
+	Aud   string `json:"aud"`
+	Iat   int64  `json:"iat"`
+	Exp   int64  `json:"exp"`
+}
+
+func (ts *signJWTTokenSource) Token() (*oauth2.Token, error) {
+	now := time.Now()
+	claims, err := json.Marshal(jwtClaims{
+		Iss:   ts.serviceAccount,
+		Sub:   ts.subject,
+		Scope: strings.Join(ts.scopes, " "),
+		Aud:   ts.tokenURL,
+		Iat:   now.Unix(),
+		Exp:   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, err := ts.signer.SignJWT(ts.ctx, ts.serviceAccount, string(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type": {jwtBearerGrantType},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ts.ctx, http.MethodPost, ts.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to exchange signed JWT for a token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to exchange signed JWT for a token: %s: %s", resp.Status, body)
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("unable to parse token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("token response did not contain an access token")
+	}
+
+	return &oauth2.Token{
+		AccessToken: tokenResp.AccessToken,
+		TokenType:   tokenResp.TokenType,
+		Expiry:      now.Add(time.Duration(tokenResp.ExpiresIn) * time.Second),
+	}, nil
+}
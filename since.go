@@ -0,0 +1,186 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// ConfigChanges lists the groups whose definition changed between two
+// versions of the groups config.
+type ConfigChanges struct {
+	// Changed are the email-ids of the groups that were added or whose
+	// definition changed.
+	Changed []string
+	// Deleted are the email-ids of the groups that were removed from the
+	// config.
+	Deleted []string
+}
+
+// changedFile is a groups config file that differs between a git ref and
+// the working tree.
+type changedFile struct {
+	// path is relative to the groups-path, with forward slashes.
+	path string
+	// existed is true if the file existed at the ref.
+	existed bool
+}
+
+// git runs git in dir and returns its standard output.
+func git(dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// changedGroupsFiles returns the groups config files under rootDir that were
+// added, modified or deleted between ref and the working tree, including the
+// untracked ones.
+func changedGroupsFiles(rootDir, ref string, opts GroupsLoadOptions) ([]changedFile, error) {
+	ignore, err := loadIgnoreFile(filepath.Join(rootDir, defaultIgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+	isGroupsFile := func(p string) bool {
+		if !matchesAnyPattern(path.Base(p), opts.Patterns) {
+			return false
+		}
+		for dir := p; dir != "."; dir = path.Dir(dir) {
+			if isIgnored(dir, ignore) {
+				return false
+			}
+		}
+		return true
+	}
+
+	diff, err := git(rootDir, "diff", "--name-status", "--no-renames", "--relative", ref, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	var files []changedFile
+	for _, line := range strings.Split(strings.TrimSpace(string(diff)), "\n") {
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 || !isGroupsFile(fields[1]) {
+			continue
+		}
+		files = append(files, changedFile{path: fields[1], existed: fields[0] != "A"})
+	}
+
+	untracked, err := git(rootDir, "ls-files", "--others", "--exclude-standard", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range strings.Split(strings.TrimSpace(string(untracked)), "\n") {
+		if p != "" && isGroupsFile(p) {
+			files = append(files, changedFile{path: p})
+		}
+	}
+	return files, nil
+}
+
+// ChangesSince returns the groups of the config loaded from rootDir, groups,
+// whose definition changed since the git ref. Groups removed from a file are
+// reported as deleted unless they are still defined in another file.
+func ChangesSince(rootDir, ref string, opts GroupsLoadOptions, groups []GoogleGroup) (*ConfigChanges, error) {
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid git ref %q", ref)
+	}
+	if len(opts.Patterns) == 0 {
+		opts.Patterns = []string{defaultGroupsFile}
+	}
+	files, err := changedGroupsFiles(rootDir, ref, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	before := map[string]GoogleGroup{}
+	changedPaths := map[string]bool{}
+	for _, f := range files {
+		changedPaths[f.path] = true
+		if !f.existed {
+			continue
+		}
+		content, err := git(rootDir, "show", ref+":./"+f.path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var gc GroupsConfig
+		if err := unmarshalGroupsConfig(f.path, content, &gc); err != nil {
+			errs = append(errs, fmt.Errorf("error parsing groups config at %s in %s: %w", f.path, ref, err))
+			continue
+		}
+		for _, g := range gc.Groups {
+			before[g.EmailId] = g
+		}
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	changes := &ConfigChanges{}
+	current := map[string]bool{}
+	for _, g := range groups {
+		current[g.EmailId] = true
+		if !changedPaths[g.Path] {
+			continue
+		}
+		old, ok := before[g.EmailId]
+		old.Path = g.Path
+		if !ok || !reflect.DeepEqual(old, g) {
+			changes.Changed = append(changes.Changed, g.EmailId)
+		}
+	}
+	for email := range before {
+		if !current[email] {
+			changes.Deleted = append(changes.Deleted, email)
+		}
+	}
+	sort.Strings(changes.Changed)
+	sort.Strings(changes.Deleted)
+	return changes, nil
+}
+
+// ReconcileChanges reconciles the groups of the config, groups, that were
+// changed, and deletes the ones that were deleted.
+func (r *Reconciler) ReconcileChanges(groups []GoogleGroup, changes *ConfigChanges) error {
+	var errs []error
+	if len(changes.Changed) > 0 {
+		if err := r.ReconcileSelectedGroups(groups, GroupFilter{Groups: changes.Changed}, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := r.adminService.DeleteGroups(changes.Deleted); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestChangesSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		writeTree(t, root, map[string]string{path: content})
+	}
+	run := func(args ...string) {
+		t.Helper()
+		if _, err := git(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("groups.yaml", `groups:
+  - email-id: unchanged@example.com
+    owners: [owner@example.com]
+  - email-id: changed@example.com
+    owners: [owner@example.com]
+  - email-id: removed@example.com
+  - email-id: moved@example.com
+`)
+	write("sig/groups.yaml", `groups:
+  - email-id: deleted-file@example.com
+`)
+	run("init", "-q")
+	run("add", ".")
+	run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+
+	write("groups.yaml", `# a comment does not change any group
+groups:
+  - email-id: unchanged@example.com
+    owners: [owner@example.com]
+  - email-id: changed@example.com
+    owners: [owner@example.com, new@example.com]
+`)
+	run("rm", "-q", "sig/groups.yaml")
+	write("other/groups.yaml", `groups:
+  - email-id: moved@example.com
+  - email-id: added@example.com
+`)
+
+	var gc GroupsConfig
+	if err := gc.Load(root, GroupsLoadOptions{}, &RestrictionsConfig{Restrictions: []Restriction{defaultRestriction}}); err != nil {
+		t.Fatalf("unexpected error loading groups: %v", err)
+	}
+	changes, err := ChangesSince(root, "HEAD", GroupsLoadOptions{}, gc.Groups)
+	if err != nil {
+		t.Fatalf("unexpected error computing changes: %v", err)
+	}
+	expected := &ConfigChanges{
+		Changed: []string{"added@example.com", "changed@example.com"},
+		Deleted: []string{"deleted-file@example.com", "removed@example.com"},
+	}
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("unexpected changes:\nexpected %+v\ngot      %+v", expected, changes)
+	}
+
+	r, ac, gc2 := newFakeReconciler()
+	for _, email := range []string{"unchanged@example.com", "changed@example.com", "removed@example.com", "moved@example.com", "unmanaged@example.com"} {
+		addFakeGroup(ac, gc2, email, defaultSettings(), "owner@example.com", OwnerRole)
+	}
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+	if err := r.ReconcileChanges(gc.Groups, changes); err != nil {
+		t.Fatalf("unexpected error reconciling changes: %v", err)
+	}
+	expectedCalls := []string{
+		"InsertMember changed@example.com new@example.com OWNER",
+		"InsertGroup added@example.com",
+		"DeleteGroup removed@example.com",
+	}
+	if !reflect.DeepEqual(expectedCalls, ac.calls) {
+		t.Errorf("unexpected calls:\nexpected %v\ngot      %v", expectedCalls, ac.calls)
+	}
+
+	if _, err := ChangesSince(root, "--output=/tmp/x", GroupsLoadOptions{}, gc.Groups); err == nil {
+		t.Errorf("expected an error for a ref that looks like a flag")
+	}
+}
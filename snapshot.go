@@ -0,0 +1,274 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// snapshotHeader is written at the top of every snapshot.
+const snapshotHeader = "# Snapshot of the live state of the groups taken by ggreconcile before applying changes.\n# Restore it with: ggreconcile rollback <this file>\n"
+
+// Snapshot is the live state of the groups of a tenant at some point in
+// time. Its groups are a GroupsConfig reconciling to that state.
+type Snapshot struct {
+	Tenant string    `yaml:"tenant"`
+	Time   time.Time `yaml:"time"`
+	// Scope is the email-ids of the groups the snapshot covers, including
+	// the ones that did not exist when it was taken. It is empty if the
+	// snapshot covers every group of the tenant.
+	Scope        []string `yaml:"scope,omitempty"`
+	GroupsConfig `yaml:",inline"`
+}
+
+// defaultSnapshotDir returns the directory snapshots are written to when the
+// config doesn't specify one.
+func defaultSnapshotDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "ggreconcile", "snapshots")
+}
+
+// ApplyPlan describes the groups an apply is about to touch.
+type ApplyPlan struct {
+	// Config is every group of the config.
+	Config []GoogleGroup
+	// Groups are the groups of the config about to be reconciled.
+	Groups []GoogleGroup
+	// Deleted are the email-ids of the groups about to be deleted, if they
+	// exist.
+	Deleted []string
+	// DeleteUnconfigured is true if the live groups that are not in Config
+	// are about to be deleted.
+	DeleteUnconfigured bool
+}
+
+// fullApplyPlan returns the plan of reconciling every group of groups,
+// deleting the live groups that are not in it.
+func fullApplyPlan(groups []GoogleGroup) ApplyPlan {
+	return ApplyPlan{Config: groups, Groups: groups, DeleteUnconfigured: true}
+}
+
+// isFull returns true if p touches every group of the tenant.
+func (p ApplyPlan) isFull() bool {
+	return p.DeleteUnconfigured && len(p.Groups) == len(p.Config)
+}
+
+// hasChanges returns true if applying p would change live, the live state of
+// the groups it touches.
+func (p ApplyPlan) hasChanges(live []GoogleGroup, now time.Time) bool {
+	liveByEmail := make(map[string]GoogleGroup, len(live))
+	for _, l := range live {
+		liveByEmail[strings.ToLower(l.EmailId)] = l
+	}
+	planned := make(map[string]bool, len(p.Groups))
+	for _, g := range p.Groups {
+		planned[strings.ToLower(g.EmailId)] = true
+		l, ok := liveByEmail[strings.ToLower(g.EmailId)]
+		if !ok || len(groupDrift(g.withoutExpiredMembers(now), l)) > 0 {
+			return true
+		}
+	}
+	// Every other live group is about to be deleted.
+	for email := range liveByEmail {
+		if !planned[email] {
+			return true
+		}
+	}
+	return false
+}
+
+// TakeSnapshot writes the live state of the groups of the tenant t that p is
+// about to touch to a new file in dir and returns its path. Nothing is
+// written, and the path is empty, if applying p would change none of them.
+// Every setting is recorded, even the ones left to their default, so that
+// restoring the snapshot undoes changes to defaults.
+func (r *Reconciler) TakeSnapshot(t *Tenant, dir string, now time.Time, p ApplyPlan) (string, error) {
+	configured := make(map[string]bool, len(p.Config))
+	for _, g := range p.Config {
+		configured[strings.ToLower(g.EmailId)] = true
+	}
+	scope := map[string]bool{}
+	for _, g := range p.Groups {
+		scope[strings.ToLower(g.EmailId)] = true
+	}
+	for _, email := range p.Deleted {
+		scope[strings.ToLower(email)] = true
+	}
+	groups, err := r.liveGroupsWithSettings(allSettings, func(email string) bool {
+		email = strings.ToLower(email)
+		return scope[email] || p.DeleteUnconfigured && !configured[email]
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to snapshot the groups: %w", err)
+	}
+	if !p.hasChanges(groups, now) {
+		return "", nil
+	}
+
+	now = now.UTC()
+	s := Snapshot{Tenant: t.Name, Time: now, GroupsConfig: GroupsConfig{Groups: groups}}
+	if !p.isFull() {
+		for _, g := range groups {
+			scope[strings.ToLower(g.EmailId)] = true
+		}
+		for email := range scope {
+			s.Scope = append(s.Scope, email)
+		}
+		sort.Strings(s.Scope)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.yaml", t.Name, now.Format("20060102T150405.000Z")))
+	if err := writeYAMLFile(path, snapshotHeader, s); err != nil {
+		return "", fmt.Errorf("unable to snapshot the groups: %w", err)
+	}
+	return path, nil
+}
+
+// snapshotBeforeApply snapshots the groups of the tenant t that p is about to
+// touch when changes are about to be applied to them. Nothing must be
+// applied if it fails.
+func snapshotBeforeApply(t *Tenant, r *Reconciler, p ApplyPlan) error {
+	if !config.ConfirmChanges {
+		return nil
+	}
+	path, err := r.TakeSnapshot(t, config.SnapshotDir, time.Now(), p)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		r.log.Info("skipping the snapshot as there is no change to apply")
+		return nil
+	}
+	r.log.WithField("path", path).Info("snapshotted the groups, restore them with the rollback command")
+	return nil
+}
+
+// LoadSnapshot reads the Snapshot at path.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading snapshot %s: %w", path, err)
+	}
+	var s Snapshot
+	if err := yaml.Unmarshal(content, &s); err != nil {
+		return nil, fmt.Errorf("error parsing snapshot %s: %w", path, err)
+	}
+	if s.Tenant == "" {
+		return nil, fmt.Errorf("snapshot %s has no tenant", path)
+	}
+	return &s, nil
+}
+
+// restoreGroups returns the config that reconciles the groups back to the
+// snapshot s: members added since are removed along with groups created
+// since.
+func (s *Snapshot) restoreGroups() []GoogleGroup {
+	groups := make([]GoogleGroup, 0, len(s.Groups))
+	for _, g := range s.Groups {
+		g.MembershipPolicy = AuthoritativePolicy
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// restorePlan returns the plan of restoring the snapshot s. Only the groups
+// in its scope are touched: the ones it recorded are restored and the other
+// ones, created since, are deleted.
+func (s *Snapshot) restorePlan() ApplyPlan {
+	groups := s.restoreGroups()
+	if len(s.Scope) == 0 {
+		return fullApplyPlan(groups)
+	}
+	recorded := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		recorded[strings.ToLower(g.EmailId)] = true
+	}
+	p := ApplyPlan{Config: groups, Groups: groups}
+	for _, email := range s.Scope {
+		if !recorded[strings.ToLower(email)] {
+			p.Deleted = append(p.Deleted, email)
+		}
+	}
+	return p
+}
+
+// RestoreSnapshot reconciles the groups in the scope of the snapshot s back
+// to it.
+func (r *Reconciler) RestoreSnapshot(s *Snapshot) error {
+	p := s.restorePlan()
+	if p.isFull() {
+		return r.ReconcileGroups(p.Groups)
+	}
+	changes := &ConfigChanges{Deleted: p.Deleted}
+	for _, g := range p.Groups {
+		changes.Changed = append(changes.Changed, g.EmailId)
+	}
+	return r.ReconcileChanges(p.Groups, changes)
+}
+
+// rollbackCommand registers the flags of the rollback command, which restores
+// the groups of a tenant to a snapshot taken before changes were applied.
+func rollbackCommand() func(configFilePath string) error {
+	confirmChanges := flag.Bool("confirm", false, "false by default means that we do not push anything to google groups")
+
+	return func(configFilePath string) error {
+		if flag.NArg() != 1 {
+			return fmt.Errorf("rollback: expected the path to a single snapshot, got %v", flag.Args())
+		}
+		s, err := LoadSnapshot(flag.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		if err := loadConfig(configFilePath, *confirmChanges); err != nil {
+			return err
+		}
+		var tenant *Tenant
+		for i := range config.Tenants {
+			if config.Tenants[i].Name == s.Tenant {
+				tenant = &config.Tenants[i]
+			}
+		}
+		if tenant == nil {
+			return fmt.Errorf("rollback: snapshot %s is for tenant %q which is not in the config", flag.Arg(0), s.Tenant)
+		}
+		config.Tenants = []Tenant{*tenant}
+
+		mode := PlanMode
+		if config.ConfirmChanges {
+			mode = ApplyMode
+		}
+		return forEachTenant(mode, func(t *Tenant, r *Reconciler) (string, error) {
+			logger.Infof("rolling back tenant %s to its snapshot of %v", t.Name, s.Time)
+			if err := snapshotBeforeApply(t, r, s.restorePlan()); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d groups restored", len(s.Groups)), r.RestoreSnapshot(s)
+		})
+	}
+}
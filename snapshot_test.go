@@ -0,0 +1,139 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+func TestSnapshotRollback(t *testing.T) {
+	r, ac, gc := newFakeReconciler()
+	settings := defaultSettings()
+	settings.AllowWebPosting = "false"
+	addFakeGroup(ac, gc, "team@example.com", settings,
+		"owner@example.com", OwnerRole, "other-owner@example.com", OwnerRole, "member@example.com", MemberRole)
+	addFakeGroup(ac, gc, "doomed@example.com", defaultSettings(), "owner@example.com", OwnerRole)
+
+	// A bad config removes an owner, adds a member, resets a setting,
+	// deletes a group and creates another.
+	bad := []GoogleGroup{
+		{EmailId: "team@example.com", Name: "team", Owners: []string{"other-owner@example.com"}, Members: []string{"member@example.com", "intruder@example.com"}},
+		{EmailId: "new@example.com"},
+	}
+
+	tenant := &Tenant{Name: "default"}
+	path, err := r.TakeSnapshot(tenant, t.TempDir(), time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), fullApplyPlan(bad))
+	if err != nil {
+		t.Fatalf("unexpected error taking snapshot: %v", err)
+	}
+	before, err := r.liveGroupsWithSettings(allSettings, nil)
+	if err != nil {
+		t.Fatalf("unexpected error listing groups: %v", err)
+	}
+
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+
+	if err := r.ReconcileGroups(bad); err != nil {
+		t.Fatalf("unexpected error applying the bad config: %v", err)
+	}
+	gc.settings["new@example.com"] = defaultSettings()
+	gc.settings["team@example.com"].AllowWebPosting = "true"
+
+	s, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading snapshot: %v", err)
+	}
+	if s.Tenant != "default" || !s.Time.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected snapshot tenant or time: %s %v", s.Tenant, s.Time)
+	}
+	if len(s.Scope) != 0 {
+		t.Errorf("expected a snapshot of every group to have no scope, got %v", s.Scope)
+	}
+	if err := r.RestoreSnapshot(s); err != nil {
+		t.Fatalf("unexpected error rolling back: %v", err)
+	}
+
+	after, err := r.liveGroupsWithSettings(allSettings, nil)
+	if err != nil {
+		t.Fatalf("unexpected error listing groups: %v", err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("expected the rollback to restore the snapshot:\nexpected %+v\ngot      %+v", before, after)
+	}
+}
+
+func TestPartialSnapshot(t *testing.T) {
+	r, ac, gc := newFakeReconciler()
+	addFakeGroup(ac, gc, "team@example.com", defaultSettings(), "owner@example.com", OwnerRole)
+	addFakeGroup(ac, gc, "other@example.com", defaultSettings(), "owner@example.com", OwnerRole)
+	groups := []GoogleGroup{
+		{EmailId: "team@example.com", Owners: []string{"owner@example.com"}},
+		{EmailId: "other@example.com", Owners: []string{"owner@example.com"}},
+	}
+	tenant := &Tenant{Name: "default"}
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if path, err := r.TakeSnapshot(tenant, dir, now, fullApplyPlan(groups)); err != nil || path != "" {
+		t.Errorf("expected no snapshot without changes, got %q, %v", path, err)
+	}
+
+	// Only team@ and the group it creates are touched.
+	changed := []GoogleGroup{
+		{EmailId: "team@example.com", Owners: []string{"owner@example.com"}, Members: []string{"member@example.com"}},
+		{EmailId: "new@example.com", Owners: []string{"owner@example.com"}},
+		groups[1],
+	}
+	p := ApplyPlan{Config: changed, Groups: changed[:2]}
+	path, err := r.TakeSnapshot(tenant, dir, now, p)
+	if err != nil || path == "" {
+		t.Fatalf("expected a snapshot, got %q, %v", path, err)
+	}
+	s, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading snapshot: %v", err)
+	}
+	if expected := []string{"new@example.com", "team@example.com"}; !reflect.DeepEqual(expected, s.Scope) {
+		t.Errorf("expected scope %v, got %v", expected, s.Scope)
+	}
+	if len(s.Groups) != 1 || s.Groups[0].EmailId != "team@example.com" {
+		t.Errorf("expected only team@example.com to be snapshotted, got %+v", s.Groups)
+	}
+
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+	if err := r.ReconcileSelectedGroups(changed, GroupFilter{Groups: []string{"team@example.com", "new@example.com"}}, false); err != nil {
+		t.Fatalf("unexpected error applying the changes: %v", err)
+	}
+	gc.settings["new@example.com"] = defaultSettings()
+	// A change made since to a group outside the scope is kept.
+	ac.members["other@example.com"] = append(ac.members["other@example.com"], &admin.Member{Email: "kept@example.com", Id: "kept@example.com", Role: MemberRole})
+
+	ac.calls = nil
+	if err := r.RestoreSnapshot(s); err != nil {
+		t.Fatalf("unexpected error rolling back: %v", err)
+	}
+	expected := []string{"DeleteMember team@example.com member@example.com", "DeleteGroup new@example.com"}
+	if !reflect.DeepEqual(expected, ac.calls) {
+		t.Errorf("expected calls %q, got %q", expected, ac.calls)
+	}
+}
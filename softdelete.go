@@ -0,0 +1,322 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+)
+
+// pendingDeletionPrefix is prepended to the description of the groups that
+// are pending deletion.
+const pendingDeletionPrefix = "[pending deletion] "
+
+// defaultGracePeriod is how long groups stay pending deletion when neither
+// the grace period nor the number of runs is configured.
+const defaultGracePeriod = 7 * 24 * time.Hour
+
+// defaultStateFile is the soft-delete state file used when none is
+// configured, relative to the directory containing the config.yaml file.
+const defaultStateFile = "soft-delete-state.json"
+
+// SoftDeleteConfig makes groups removed from the config pending deletion
+// before they are deleted: they are archived, nobody can post to them and
+// their description says so. They are only deleted once they have been
+// pending for both GracePeriod and Runs, and are restored if they are added
+// back to the config in the meantime.
+type SoftDeleteConfig struct {
+	// GracePeriod is how long a group stays pending deletion, such as "168h".
+	GracePeriod time.Duration `yaml:"grace-period,omitempty"`
+
+	// Runs is how many runs must find the group still missing from the
+	// config after it was marked before it is deleted.
+	Runs int `yaml:"runs,omitempty"`
+
+	// StateFile is the path to the file keeping track of the groups pending
+	// deletion. Relative paths are relative to the directory containing the
+	// config.yaml file. If not specified, it defaults to
+	// soft-delete-state.json next to the config.yaml file, so that it is
+	// kept alongside the config rather than in a cache that may be wiped.
+	StateFile string `yaml:"state-file,omitempty"`
+}
+
+// setDefaults fills in the unset fields of c, resolving relative paths
+// against configDir.
+func (c *SoftDeleteConfig) setDefaults(configDir string) error {
+	if c.GracePeriod < 0 || c.Runs < 0 {
+		return fmt.Errorf("soft-delete grace-period and runs must not be negative")
+	}
+	if c.GracePeriod == 0 && c.Runs == 0 {
+		c.GracePeriod = defaultGracePeriod
+	}
+	if c.StateFile == "" {
+		c.StateFile = defaultStateFile
+	}
+	if !filepath.IsAbs(c.StateFile) {
+		c.StateFile = filepath.Join(configDir, c.StateFile)
+	}
+	return nil
+}
+
+// PendingDeletion is the state of a group pending deletion.
+type PendingDeletion struct {
+	// Since is when the group was marked.
+	Since time.Time `json:"since"`
+	// Runs is how many runs found the group missing from the config since
+	// it was marked.
+	Runs int `json:"runs"`
+
+	// The values the group had before it was marked, to restore them if
+	// it is added back to the config.
+	Description       string `json:"description,omitempty"`
+	IsArchived        string `json:"isArchived,omitempty"`
+	WhoCanPostMessage string `json:"whoCanPostMessage,omitempty"`
+}
+
+// softDeleteState maps the name of each tenant to its groups pending
+// deletion, by email.
+type softDeleteState map[string]map[string]*PendingDeletion
+
+func loadSoftDeleteState(path string) (softDeleteState, error) {
+	state := softDeleteState{}
+	content, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading soft-delete state file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("error parsing soft-delete state file %s: %w", path, err)
+	}
+	return state, nil
+}
+
+func (s softDeleteState) save(path string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode soft-delete state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Write then rename so that an interrupted run never leaves a truncated
+	// state file behind.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("unable to write soft-delete state file: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// softDeleter deletes the groups of a tenant in two phases, following a
+// SoftDeleteConfig.
+type softDeleter struct {
+	config   SoftDeleteConfig
+	tenant   string
+	client   AdminServiceClient
+	settings GroupServiceClient
+	log      *logrus.Entry
+	now      func() time.Time
+
+	// pending are the groups of the tenant pending deletion, loaded from
+	// the state file on first use so that it is only read once per run.
+	pending map[string]*PendingDeletion
+}
+
+// update passes the groups of the tenant pending deletion to fn and saves
+// them if fn changed them.
+func (sd *softDeleter) update(fn func(pending map[string]*PendingDeletion) (bool, error)) error {
+	if sd.pending == nil {
+		state, err := loadSoftDeleteState(sd.config.StateFile)
+		if err != nil {
+			return err
+		}
+		sd.pending = state[sd.tenant]
+		if sd.pending == nil {
+			sd.pending = map[string]*PendingDeletion{}
+		}
+	}
+	changed, err := fn(sd.pending)
+	if !changed {
+		return err
+	}
+
+	// The state file is shared by all the tenants, so it is read again to
+	// only replace the groups of this one.
+	state, loadErr := loadSoftDeleteState(sd.config.StateFile)
+	if loadErr != nil {
+		return loadErr
+	}
+	if len(sd.pending) == 0 {
+		delete(state, sd.tenant)
+	} else {
+		state[sd.tenant] = sd.pending
+	}
+	if saveErr := state.save(sd.config.StateFile); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// Delete marks the group with the given email as pending deletion the first
+// time it is called for it, and deletes it once it has been pending long
+// enough.
+func (sd *softDeleter) Delete(email string) error {
+	return sd.update(func(pending map[string]*PendingDeletion) (bool, error) {
+		p, ok := pending[email]
+		if !ok {
+			return sd.mark(pending, email)
+		}
+
+		runs := p.Runs + 1
+		due := sd.now().Sub(p.Since) >= sd.config.GracePeriod && runs >= sd.config.Runs
+		log := changeLog(sd.log, DeleteGroupChange, email).WithFields(logrus.Fields{"pendingSince": p.Since, "runs": runs})
+		if !due {
+			log.Info("group is pending deletion")
+			if !config.ConfirmChanges {
+				return false, nil
+			}
+			p.Runs = runs
+			return true, nil
+		}
+
+		if !config.ConfirmChanges {
+			recordChange(sd.tenant, DeleteGroupChange, false)
+			log.Info("would remove group pending deletion")
+			return false, nil
+		}
+		if err := sd.client.DeleteGroup(email); err != nil {
+			return true, fmt.Errorf("unable to remove group %s : %w", email, err)
+		}
+		recordChange(sd.tenant, DeleteGroupChange, true)
+		delete(pending, email)
+		log.Info("removed group pending deletion")
+		return true, nil
+	})
+}
+
+// mark archives the group with the given email, stops anyone from posting to
+// it and prefixes its description, recording the values it had in pending.
+func (sd *softDeleter) mark(pending map[string]*PendingDeletion, email string) (bool, error) {
+	log := changeLog(sd.log, SoftDeleteGroupChange, email)
+	if !config.ConfirmChanges {
+		recordChange(sd.tenant, SoftDeleteGroupChange, false)
+		log.Info("would mark group as pending deletion")
+		return false, nil
+	}
+
+	g, err := sd.client.GetGroup(email)
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch group %q: %w", email, err)
+	}
+	s, err := sd.settings.Get(email)
+	if err != nil {
+		return false, fmt.Errorf("unable to retrieve group info for group %q: %w", email, err)
+	}
+	p := &PendingDeletion{
+		Since:             sd.now().UTC(),
+		Description:       g.Description,
+		IsArchived:        s.IsArchived,
+		WhoCanPostMessage: s.WhoCanPostMessage,
+	}
+	if strings.HasPrefix(g.Description, pendingDeletionPrefix) {
+		// The group was already marked but its state was lost: the values
+		// set by the marking are not the ones to restore.
+		p.Description = strings.TrimPrefix(g.Description, pendingDeletionPrefix)
+		if p.IsArchived == "true" {
+			p.IsArchived = ""
+		}
+		if p.WhoCanPostMessage == "NONE_CAN_POST" {
+			p.WhoCanPostMessage = ""
+		}
+	}
+
+	// The state is saved even if marking fails halfway, so that the group
+	// can be restored.
+	pending[email] = p
+	if _, err := sd.settings.Patch(email, &groupssettings.Groups{IsArchived: "true", WhoCanPostMessage: "NONE_CAN_POST"}); err != nil {
+		return true, fmt.Errorf("unable to archive group %q: %w", email, err)
+	}
+	if !strings.HasPrefix(g.Description, pendingDeletionPrefix) {
+		update := &admin.Group{Email: email, Name: g.Name, Description: pendingDeletionPrefix + g.Description}
+		if _, err := sd.client.UpdateGroup(email, update); err != nil {
+			return true, fmt.Errorf("unable to update group %q: %w", email, err)
+		}
+	}
+	recordChange(sd.tenant, SoftDeleteGroupChange, true)
+	log.Info("marked group as pending deletion")
+	return true, nil
+}
+
+// Restore undoes the marking of the group with the given email if it is
+// pending deletion, as it is back in the config.
+func (sd *softDeleter) Restore(email string) error {
+	return sd.update(func(pending map[string]*PendingDeletion) (bool, error) {
+		p, ok := pending[email]
+		if !ok {
+			return false, nil
+		}
+
+		log := changeLog(sd.log, RestoreGroupChange, email)
+		if !config.ConfirmChanges {
+			recordChange(sd.tenant, RestoreGroupChange, false)
+			log.Info("would restore group pending deletion")
+			return false, nil
+		}
+
+		g, err := sd.client.GetGroup(email)
+		if err != nil {
+			if apierr, ok := err.(*googleapi.Error); ok && apierr.Code == http.StatusNotFound {
+				// The group was deleted by someone else, it will be
+				// created again.
+				delete(pending, email)
+				return true, nil
+			}
+			return false, fmt.Errorf("unable to fetch group %q: %w", email, err)
+		}
+		isArchived := p.IsArchived
+		if isArchived == "" {
+			isArchived = "false"
+		}
+		if _, err := sd.settings.Patch(email, &groupssettings.Groups{IsArchived: isArchived, WhoCanPostMessage: p.WhoCanPostMessage}); err != nil {
+			return false, fmt.Errorf("unable to unarchive group %q: %w", email, err)
+		}
+		if strings.HasPrefix(g.Description, pendingDeletionPrefix) {
+			update := &admin.Group{Email: email, Name: g.Name, Description: p.Description, ForceSendFields: []string{"Description"}}
+			if _, err := sd.client.UpdateGroup(email, update); err != nil {
+				return false, fmt.Errorf("unable to update group %q: %w", email, err)
+			}
+		}
+		delete(pending, email)
+		recordChange(sd.tenant, RestoreGroupChange, true)
+		log.Info("restored group pending deletion")
+		return true, nil
+	})
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSoftDelete(t *testing.T) {
+	ac := newFakeAdminServiceClient()
+	gc := newFakeGroupServiceClient()
+	addFakeGroup(ac, gc, "stale@example.com", defaultSettings())
+	addFakeGroup(ac, gc, "back@example.com", defaultSettings())
+	ac.groups["back@example.com"].Description = "came back"
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := discardLogger()
+	sd := &softDeleter{
+		config:   SoftDeleteConfig{GracePeriod: 48 * time.Hour, Runs: 2, StateFile: filepath.Join(t.TempDir(), "state.json")},
+		tenant:   "default",
+		client:   ac,
+		settings: gc,
+		log:      log,
+		now:      func() time.Time { return now },
+	}
+	as := &adminService{client: ac, log: log, tenant: "default", softDelete: sd}
+
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+
+	pending := func() map[string]*PendingDeletion {
+		t.Helper()
+		state, err := loadSoftDeleteState(sd.config.StateFile)
+		if err != nil {
+			t.Fatalf("unexpected error loading state: %v", err)
+		}
+		return state["default"]
+	}
+
+	// The first run marks both groups.
+	if err := as.DeleteGroupsIfNecessary(nil); err != nil {
+		t.Fatalf("unexpected error deleting groups: %v", err)
+	}
+	if _, ok := ac.groups["stale@example.com"]; !ok {
+		t.Fatalf("expected the group to be kept when it is marked")
+	}
+	if s := gc.settings["stale@example.com"]; s.IsArchived != "true" || s.WhoCanPostMessage != "NONE_CAN_POST" {
+		t.Errorf("expected the group to be archived and closed to posts, got %+v", s)
+	}
+	if d := ac.groups["back@example.com"].Description; d != "[pending deletion] came back" {
+		t.Errorf("unexpected description of a group pending deletion: %q", d)
+	}
+	if p := pending(); len(p) != 2 || !p["stale@example.com"].Since.Equal(now) {
+		t.Errorf("expected both groups to be pending deletion, got %v", p)
+	}
+
+	// A group added back to the config is restored.
+	if err := as.CreateOrUpdateGroupIfNescessary(GoogleGroup{EmailId: "back@example.com"}); err != nil {
+		t.Fatalf("unexpected error restoring group: %v", err)
+	}
+	if d := ac.groups["back@example.com"].Description; d != "came back" {
+		t.Errorf("expected the description to be restored, got %q", d)
+	}
+	if s := gc.settings["back@example.com"]; s.IsArchived != "false" || s.WhoCanPostMessage != "ALL_MEMBERS_CAN_POST" {
+		t.Errorf("expected the settings to be restored, got %+v", s)
+	}
+
+	// The group is only deleted once it has been pending for both the
+	// grace period and the number of runs: the second run is still within
+	// the grace period.
+	configured := []GoogleGroup{{EmailId: "back@example.com"}}
+	for i, expectDeleted := range []bool{false, false, true} {
+		now = now.Add(time.Duration(i) * 24 * time.Hour)
+		ac.calls = nil
+		if err := as.DeleteGroupsIfNecessary(configured); err != nil {
+			t.Fatalf("unexpected error deleting groups: %v", err)
+		}
+		var expected []string
+		if expectDeleted {
+			expected = []string{"DeleteGroup stale@example.com"}
+		}
+		if !reflect.DeepEqual(expected, ac.calls) {
+			t.Errorf("run %d: unexpected calls: expected %v, got %v", i, expected, ac.calls)
+		}
+	}
+	if p := pending(); len(p) != 0 {
+		t.Errorf("expected no group to be pending deletion, got %v", p)
+	}
+}
+
+func TestSoftDeleteMarkAgain(t *testing.T) {
+	ac := newFakeAdminServiceClient()
+	gc := newFakeGroupServiceClient()
+	addFakeGroup(ac, gc, "stale@example.com", defaultSettings())
+	ac.groups["stale@example.com"].Description = "the stale group"
+
+	newSoftDeleter := func() *softDeleter {
+		return &softDeleter{
+			config:   SoftDeleteConfig{GracePeriod: 48 * time.Hour, StateFile: filepath.Join(t.TempDir(), "state.json")},
+			tenant:   "default",
+			client:   ac,
+			settings: gc,
+			log:      discardLogger(),
+			now:      time.Now,
+		}
+	}
+
+	config.ConfirmChanges = true
+	defer func() { config.ConfirmChanges = false }()
+
+	// The state file of the first marking is lost, as each soft deleter
+	// has its own.
+	if err := newSoftDeleter().Delete("stale@example.com"); err != nil {
+		t.Fatalf("unexpected error marking group: %v", err)
+	}
+	sd := newSoftDeleter()
+	if err := sd.Delete("stale@example.com"); err != nil {
+		t.Fatalf("unexpected error marking group again: %v", err)
+	}
+	if d := ac.groups["stale@example.com"].Description; d != "[pending deletion] the stale group" {
+		t.Errorf("expected the description to be prefixed once, got %q", d)
+	}
+
+	if err := sd.Restore("stale@example.com"); err != nil {
+		t.Fatalf("unexpected error restoring group: %v", err)
+	}
+	if d := ac.groups["stale@example.com"].Description; d != "the stale group" {
+		t.Errorf("expected the description to be restored, got %q", d)
+	}
+	if s := gc.settings["stale@example.com"]; s.IsArchived != "false" {
+		t.Errorf("expected the group to be unarchived, got %+v", s)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// validateGroups returns an error for each problem in groups, the groups
+// config of a tenant, that loading it does not catch, as of now.
+func validateGroups(groups []GoogleGroup, now time.Time) error {
+	var errs []error
+	for _, g := range groups {
+		for _, m := range g.expiredMembers(now) {
+			errs = append(errs, fmt.Errorf("group %q: membership of %s expired on %s, remove it from %s", g.EmailId, m.Email, m.Expires.Format(expiryDateFormat), g.Path))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateCommand registers the flags of the validate command, which loads
+// the config and the groups of every tenant and checks them without calling
+// any API.
+func validateCommand() func(configFilePath string) error {
+	return func(configFilePath string) error {
+		if err := loadConfig(configFilePath, false); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var errs []error
+		for i := range config.Tenants {
+			t := &config.Tenants[i]
+			log := logger.WithField(tenantField, t.Name)
+			groupsConfig, err := loadTenantGroups(t)
+			if err == nil {
+				for _, g := range groupsConfig.Groups {
+					logExpiringMembers(log, g, now, config.ExpiryWarningDays)
+				}
+				err = validateGroups(groupsConfig.Groups, now)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("tenant %s: %w", t.Name, err))
+				continue
+			}
+			log.Infof("%d groups are valid", len(groupsConfig.Groups))
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Membership is a group an email is in, directly or through nested groups.
+type Membership struct {
+	Group string
+	// Role is the role of the email in Group, or of the last group of Via
+	// if the membership is transitive.
+	Role string
+	// Via is the chain of nested groups the email is in Group through, from
+	// the one the email is a direct member of. It is empty for direct
+	// memberships.
+	Via []string
+	// Path is the file Group is defined in, empty for live memberships.
+	Path string
+	// Expires is the expiry date of a direct membership in the config, zero
+	// if it never expires.
+	Expires time.Time
+}
+
+func (m Membership) String() string {
+	s := fmt.Sprintf("%s of %s", m.Role, m.Group)
+	if len(m.Via) > 0 {
+		s += " via " + strings.Join(m.Via, " > ")
+	}
+	if !m.Expires.IsZero() {
+		s += ", expires " + m.Expires.Format(expiryDateFormat)
+	}
+	if m.Path != "" {
+		s += " (" + m.Path + ")"
+	}
+	return s
+}
+
+// whois returns the memberships of email in groups, including the ones
+// through nested groups, sorted by group. When email is in a group through
+// several paths, the shortest one is reported.
+func whois(email string, groups []GoogleGroup) []Membership {
+	type subject struct {
+		email string
+		via   []string
+	}
+	seen := map[string]bool{strings.ToLower(email): true}
+	queue := []subject{{email: email}}
+	var memberships []Membership
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, g := range groups {
+			if seen[strings.ToLower(g.EmailId)] {
+				continue
+			}
+			role := groupRole(g, s.email)
+			if role == "" {
+				continue
+			}
+			seen[strings.ToLower(g.EmailId)] = true
+			m := Membership{Group: g.EmailId, Role: role, Via: s.via, Path: g.Path}
+			if len(s.via) == 0 {
+				m.Expires = g.Expires[expiryKey(role, s.email)]
+			}
+			memberships = append(memberships, m)
+			via := append(append([]string(nil), s.via...), g.EmailId)
+			queue = append(queue, subject{email: g.EmailId, via: via})
+		}
+	}
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].Group < memberships[j].Group
+	})
+	return memberships
+}
+
+// groupRole returns the role of email in g, or an empty string if it is not
+// one of its owners, managers or members.
+func groupRole(g GoogleGroup, email string) string {
+	for _, list := range []struct {
+		role    string
+		members []string
+	}{{OwnerRole, g.Owners}, {ManagerRole, g.Managers}, {MemberRole, g.Members}} {
+		for _, m := range list.members {
+			if strings.EqualFold(m, email) {
+				return list.role
+			}
+		}
+	}
+	return ""
+}
+
+// membershipDiscrepancies compares the direct memberships of an email in
+// the config, configured, with its live ones. Memberships expired at now are
+// not expected to be live.
+func membershipDiscrepancies(configured, live []Membership, now time.Time) []string {
+	direct := func(memberships []Membership) map[string]Membership {
+		byGroup := map[string]Membership{}
+		for _, m := range memberships {
+			if len(m.Via) == 0 && (m.Expires.IsZero() || !membershipExpired(m.Expires, now)) {
+				byGroup[strings.ToLower(m.Group)] = m
+			}
+		}
+		return byGroup
+	}
+	want, have := direct(configured), direct(live)
+
+	var discrepancies []string
+	for _, group := range sortedMembershipKeys(want) {
+		w := want[group]
+		h, ok := have[group]
+		switch {
+		case !ok:
+			discrepancies = append(discrepancies, fmt.Sprintf("%s of %s is in the config but not live", w.Role, w.Group))
+		case h.Role != w.Role:
+			discrepancies = append(discrepancies, fmt.Sprintf("%s of %s is live as %s", w.Role, w.Group, h.Role))
+		}
+	}
+	for _, group := range sortedMembershipKeys(have) {
+		if _, ok := want[group]; !ok {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s of %s is live but not in the config", have[group].Role, have[group].Group))
+		}
+	}
+	return discrepancies
+}
+
+func sortedMembershipKeys(m map[string]Membership) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// whoisCommand registers the flags of the whois command, which prints the
+// groups an email is in and with what role, according to the config and
+// optionally the live groups.
+func whoisCommand() func(configFilePath string) error {
+	live := flag.Bool("live", false, "also look the email up in the live groups and report how its memberships differ from the config")
+
+	return func(configFilePath string) error {
+		if flag.NArg() != 1 {
+			return fmt.Errorf("whois: expected a single email, got %v", flag.Args())
+		}
+		email := flag.Arg(0)
+		if err := loadConfig(configFilePath, false); err != nil {
+			return err
+		}
+
+		var errs []error
+		configured := map[string][]Membership{}
+		for i := range config.Tenants {
+			t := &config.Tenants[i]
+			groupsConfig, err := loadTenantGroups(t)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("tenant %s: %w", t.Name, err))
+				continue
+			}
+			configured[t.Name] = whois(email, groupsConfig.Groups)
+			for _, m := range configured[t.Name] {
+				fmt.Printf("%s: config: %s\n", t.Name, m)
+			}
+		}
+		if len(errs) > 0 || !*live {
+			return utilerrors.NewAggregate(errs)
+		}
+
+		now := time.Now()
+		return forEachTenant(PlanMode, func(t *Tenant, r *Reconciler) (string, error) {
+			groups, err := r.liveGroups()
+			if err != nil {
+				return "", err
+			}
+			memberships := whois(email, groups)
+			for _, m := range memberships {
+				fmt.Printf("%s: live: %s\n", t.Name, m)
+			}
+			discrepancies := membershipDiscrepancies(configured[t.Name], memberships, now)
+			for _, d := range discrepancies {
+				fmt.Printf("%s: differs: %s\n", t.Name, d)
+			}
+			return fmt.Sprintf("%d live memberships, %d discrepancies", len(memberships), len(discrepancies)), nil
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWhois(t *testing.T) {
+	expires := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	groups := []GoogleGroup{
+		{EmailId: "all@example.com", Path: "groups.yaml", Members: []string{"team@example.com", "leads@example.com"}},
+		{EmailId: "team@example.com", Path: "sig-foo/groups.yaml", Owners: []string{"Alice@example.com"}, Members: []string{"bob@example.com"}},
+		{EmailId: "leads@example.com", Path: "sig-foo/groups.yaml", Managers: []string{"alice@example.com"}, Expires: map[string]time.Time{expiryKey(ManagerRole, "alice@example.com"): expires}},
+		{EmailId: "admins@example.com", Path: "groups.yaml", Owners: []string{"all@example.com"}},
+		{EmailId: "other@example.com", Path: "groups.yaml", Members: []string{"bob@example.com"}},
+	}
+
+	configured := whois("alice@example.com", groups)
+	expected := []Membership{
+		{Group: "admins@example.com", Role: OwnerRole, Via: []string{"team@example.com", "all@example.com"}, Path: "groups.yaml"},
+		{Group: "all@example.com", Role: MemberRole, Via: []string{"team@example.com"}, Path: "groups.yaml"},
+		{Group: "leads@example.com", Role: ManagerRole, Path: "sig-foo/groups.yaml", Expires: expires},
+		{Group: "team@example.com", Role: OwnerRole, Path: "sig-foo/groups.yaml"},
+	}
+	if !reflect.DeepEqual(expected, configured) {
+		t.Errorf("unexpected memberships:\nexpected %+v\ngot      %+v", expected, configured)
+	}
+	if s := configured[0].String(); s != "OWNER of admins@example.com via team@example.com > all@example.com (groups.yaml)" {
+		t.Errorf("unexpected membership string: %q", s)
+	}
+
+	live := []Membership{
+		{Group: "all@example.com", Role: MemberRole},
+		{Group: "leads@example.com", Role: MemberRole},
+		{Group: "unmanaged@example.com", Role: OwnerRole},
+	}
+	expectedDiscrepancies := []string{
+		"MANAGER of leads@example.com is live as MEMBER",
+		"OWNER of team@example.com is in the config but not live",
+		"MEMBER of all@example.com is live but not in the config",
+		"OWNER of unmanaged@example.com is live but not in the config",
+	}
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	if d := membershipDiscrepancies(configured, live, now); !reflect.DeepEqual(expectedDiscrepancies, d) {
+		t.Errorf("unexpected discrepancies:\nexpected %q\ngot      %q", expectedDiscrepancies, d)
+	}
+	if d := membershipDiscrepancies(configured, live, expires.AddDate(0, 0, 1)); len(d) != 4 || d[0] != expectedDiscrepancies[1] {
+		t.Errorf("expected the expired membership not to be expected live, got %q", d)
+	}
+}
@@ -0,0 +1,701 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GroupsFile is a YAML groups config file being edited. Edits only rewrite
+// the lines of what they change, so the comments, ordering and indentation of
+// the rest of the file are kept. Groups, settings and multi-line lists in
+// flow style, such as {email-id: a@example.com}, can't be edited.
+type GroupsFile struct {
+	content []byte
+	// groups are the mapping nodes of the groups in content.
+	groups []*yaml.Node
+}
+
+// ParseGroupsFile parses content, a YAML groups config file, for editing.
+func ParseGroupsFile(content []byte) (*GroupsFile, error) {
+	f := &GroupsFile{}
+	if err := f.set(content); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// set replaces the content of f, parsing it again so that the positions of
+// its nodes match the new content.
+func (f *GroupsFile) set(content []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+	f.content = content
+	f.groups = nil
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	var gc GroupsConfig
+	if err := doc.Decode(&gc); err != nil {
+		return err
+	}
+	groups := mappingValue(doc.Content[0], "groups")
+	if groups == nil {
+		return nil
+	}
+	for _, g := range groups.Content {
+		if g.Kind == yaml.MappingNode {
+			f.groups = append(f.groups, g)
+		}
+	}
+	return nil
+}
+
+// Bytes returns the edited content of the file.
+func (f *GroupsFile) Bytes() []byte {
+	return f.content
+}
+
+// GroupIDs returns the email-ids of the groups in the file, in order.
+func (f *GroupsFile) GroupIDs() []string {
+	var ids []string
+	for _, g := range f.groups {
+		if id := mappingValue(g, "email-id"); id != nil {
+			ids = append(ids, id.Value)
+		}
+	}
+	return ids
+}
+
+// group returns the mapping node of the group with the given email-id.
+func (f *GroupsFile) group(emailID string) (*yaml.Node, error) {
+	for _, g := range f.groups {
+		if id := mappingValue(g, "email-id"); id != nil && strings.EqualFold(id.Value, emailID) {
+			if g.Style&yaml.FlowStyle != 0 {
+				return nil, fmt.Errorf("group %q is in flow style", emailID)
+			}
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("group %q is not in the file", emailID)
+}
+
+// edit applies fn to the lines of the file and parses the result again.
+func (f *GroupsFile) edit(fn func(e *lineEditor) error) error {
+	e := newLineEditor(f.content)
+	if err := fn(e); err != nil {
+		return err
+	}
+	if err := f.set(e.bytes()); err != nil {
+		return fmt.Errorf("the edit would make the file invalid: %w", err)
+	}
+	return nil
+}
+
+// Members returns the emails of the entries of role in group, in order.
+func (f *GroupsFile) Members(group, role string) ([]string, error) {
+	g, err := f.group(group)
+	if err != nil {
+		return nil, err
+	}
+	_, list := mappingEntry(g, memberListKeys[role])
+	if list == nil {
+		return nil, nil
+	}
+	emails := make([]string, 0, len(list.Content))
+	for _, entry := range list.Content {
+		emails = append(emails, entryEmail(entry))
+	}
+	return emails, nil
+}
+
+// RemoveMember removes the entries of email from the owners, managers and
+// members of group, along with the comment lines right above them, and
+// returns the roles they had. Lists left empty are removed along with their
+// key.
+func (f *GroupsFile) RemoveMember(group, email string) ([]string, error) {
+	g, err := f.group(group)
+	if err != nil {
+		return nil, err
+	}
+	var roles []string
+	err = f.edit(func(e *lineEditor) error {
+		for i := 0; i+1 < len(g.Content); i += 2 {
+			key, list := g.Content[i], g.Content[i+1]
+			role, ok := memberListRoles[key.Value]
+			if !ok || list.Kind != yaml.SequenceNode {
+				continue
+			}
+			var removed, kept []*yaml.Node
+			for _, entry := range list.Content {
+				if strings.EqualFold(entryEmail(entry), email) {
+					removed = append(removed, entry)
+				} else {
+					kept = append(kept, entry)
+				}
+			}
+			if len(removed) == 0 {
+				continue
+			}
+
+			switch {
+			case len(kept) == 0:
+				e.removeLines(key.Line, e.endLine(list))
+			case list.Style&yaml.FlowStyle != 0:
+				if err := e.replaceFlowSequence(list, kept); err != nil {
+					return fmt.Errorf("the %s of group %q: %w", key.Value, group, err)
+				}
+			default:
+				for _, entry := range removed {
+					e.removeLines(entry.Line, e.endLine(entry))
+				}
+			}
+			for range removed {
+				roles = append(roles, role)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// AddMember adds email to group with role, at the end of the list of that
+// role. Nothing is done if email already has role; it is an error if it has
+// another one.
+func (f *GroupsFile) AddMember(group, role, email string) error {
+	return f.addEntry(group, role, email, "")
+}
+
+// MoveMember gives email the role in group instead of the one it has,
+// keeping its expiry date and how it is spelled in the file.
+func (f *GroupsFile) MoveMember(group, email, role string) error {
+	g, err := f.group(group)
+	if err != nil {
+		return err
+	}
+	entry, current := findEntry(g, email)
+	if entry == nil {
+		return fmt.Errorf("%s is not in group %q", email, group)
+	}
+	if current == role {
+		return nil
+	}
+	var expires string
+	if v := mappingValue(entry, "expires"); v != nil {
+		expires = v.Value
+	}
+	if _, err := f.RemoveMember(group, email); err != nil {
+		return err
+	}
+	return f.addEntry(group, role, entryEmail(entry), expires)
+}
+
+// addEntry adds an entry for email to group with role, with an expiry date
+// if expires is not empty.
+func (f *GroupsFile) addEntry(group, role, email, expires string) error {
+	key, ok := memberListKeys[role]
+	if !ok {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	g, err := f.group(group)
+	if err != nil {
+		return err
+	}
+	if _, current := findEntry(g, email); current == role {
+		return nil
+	} else if current != "" {
+		return fmt.Errorf("%s is already a %s of group %q", email, current, group)
+	}
+
+	_, list := mappingEntry(g, key)
+	if list != nil && list.Kind != yaml.SequenceNode {
+		return fmt.Errorf("the %s of group %q are not a list", key, group)
+	}
+	indent := g.Content[0].Column - 1
+	itemIndent := indent + f.sequenceIndent()
+	return f.edit(func(e *lineEditor) error {
+		switch {
+		case list == nil:
+			e.insertAfter(e.endLine(g), spaces(indent)+key+":\n"+entryLines(itemIndent, email, expires))
+		case list.Style&yaml.FlowStyle != 0:
+			if expires != "" {
+				return fmt.Errorf("unable to add an entry with an expiry date to the %s of group %q, which are in flow style", key, group)
+			}
+			kept := append(append([]*yaml.Node(nil), list.Content...), &yaml.Node{Kind: yaml.ScalarNode, Value: email})
+			if err := e.replaceFlowSequence(list, kept); err != nil {
+				return fmt.Errorf("the %s of group %q: %w", key, group, err)
+			}
+		default:
+			itemIndent = leadingSpace(e.line(list.Content[0].Line))
+			e.insertAfter(e.endLine(list), entryLines(itemIndent, email, expires))
+		}
+		return nil
+	})
+}
+
+// SetSetting sets the setting key of group to value, in place if it is
+// already set.
+func (f *GroupsFile) SetSetting(group, key, value string) error {
+	g, err := f.group(group)
+	if err != nil {
+		return err
+	}
+	settings := mappingValue(g, "settings")
+	quoted := strconv.Quote(value)
+	return f.edit(func(e *lineEditor) error {
+		switch {
+		case settings == nil:
+			indent := spaces(g.Content[0].Column - 1)
+			e.insertAfter(e.endLine(g), indent+"settings:\n"+indent+"  "+key+": "+quoted+"\n")
+		case settings.Kind != yaml.MappingNode:
+			return fmt.Errorf("the settings of group %q are not a mapping", group)
+		case settings.Style&yaml.FlowStyle != 0:
+			return fmt.Errorf("the settings of group %q are in flow style", group)
+		default:
+			k, v := mappingEntry(settings, key)
+			if v == nil {
+				indent := spaces(settings.Content[0].Column - 1)
+				e.insertAfter(e.endLine(settings), indent+key+": "+quoted+"\n")
+				return nil
+			}
+			if v.Kind != yaml.ScalarNode || v.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+				return fmt.Errorf("the setting %s of group %q is not on a single line", key, group)
+			}
+			e.replaceScalar(v, quoted, lineComment(k, v))
+		}
+		return nil
+	})
+}
+
+// RemoveSetting removes the setting key of group, if set. The settings are
+// removed along with their key if they are left empty.
+func (f *GroupsFile) RemoveSetting(group, key string) error {
+	g, err := f.group(group)
+	if err != nil {
+		return err
+	}
+	settingsKey, settings := mappingEntry(g, "settings")
+	if settings == nil {
+		return nil
+	}
+	if settings.Style&yaml.FlowStyle != 0 {
+		return fmt.Errorf("the settings of group %q are in flow style", group)
+	}
+	k, v := mappingEntry(settings, key)
+	if v == nil {
+		return nil
+	}
+	return f.edit(func(e *lineEditor) error {
+		if len(settings.Content) == 2 {
+			e.removeLines(settingsKey.Line, e.endLine(settings))
+		} else {
+			e.removeLines(k.Line, e.endLine(v))
+		}
+		return nil
+	})
+}
+
+// Format canonicalizes the owners, managers and members of every group:
+// their emails are trimmed and lowercased, and they are sorted. Entries are
+// moved along with the comment lines right above them.
+func (f *GroupsFile) Format() error {
+	return f.edit(func(e *lineEditor) error {
+		for _, g := range f.groups {
+			for i := 0; i+1 < len(g.Content); i += 2 {
+				key, list := g.Content[i], g.Content[i+1]
+				if _, ok := memberListRoles[key.Value]; !ok || list.Kind != yaml.SequenceNode || len(list.Content) == 0 {
+					continue
+				}
+				if g.Style&yaml.FlowStyle != 0 || list.Style&yaml.FlowStyle != 0 {
+					if err := e.formatFlowSequence(list); err != nil {
+						return fmt.Errorf("the %s of group %q: %w", key.Value, g.Content[1].Value, err)
+					}
+					continue
+				}
+				e.formatBlockSequence(list)
+			}
+		}
+		return nil
+	})
+}
+
+// sequenceIndent returns how much deeper than their key the entries of the
+// member lists of the file are indented, 2 if there are none.
+func (f *GroupsFile) sequenceIndent() int {
+	e := newLineEditor(f.content)
+	for _, g := range f.groups {
+		for i := 0; i+1 < len(g.Content); i += 2 {
+			key, list := g.Content[i], g.Content[i+1]
+			if _, ok := memberListRoles[key.Value]; ok && list.Kind == yaml.SequenceNode && list.Style&yaml.FlowStyle == 0 && len(list.Content) > 0 {
+				return leadingSpace(e.line(list.Content[0].Line)) - (key.Column - 1)
+			}
+		}
+	}
+	return 2
+}
+
+// memberListRoles maps the keys of the member lists of a group to the role
+// of their entries.
+var memberListRoles = map[string]string{
+	"owners":   OwnerRole,
+	"managers": ManagerRole,
+	"members":  MemberRole,
+}
+
+// memberListKeys maps each role to the key of its member list.
+var memberListKeys = map[string]string{
+	OwnerRole:   "owners",
+	ManagerRole: "managers",
+	MemberRole:  "members",
+}
+
+// findEntry returns the entry of email in the member lists of g along with
+// its role, or nil if there is none.
+func findEntry(g *yaml.Node, email string) (*yaml.Node, string) {
+	for i := 0; i+1 < len(g.Content); i += 2 {
+		role, ok := memberListRoles[g.Content[i].Value]
+		if !ok {
+			continue
+		}
+		for _, entry := range g.Content[i+1].Content {
+			if strings.EqualFold(entryEmail(entry), email) {
+				return entry, role
+			}
+		}
+	}
+	return nil, ""
+}
+
+// entryLines returns the lines of an entry for email indented by indent,
+// with an expiry date if expires is not empty.
+func entryLines(indent int, email, expires string) string {
+	if expires == "" {
+		return spaces(indent) + "- " + email + "\n"
+	}
+	return spaces(indent) + "- email: " + email + "\n" + spaces(indent+2) + "expires: " + expires + "\n"
+}
+
+// normalizeEmail returns the canonical form of an email in the config.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// mappingEntry returns the key and value nodes of key in the mapping node n,
+// or nils if n is not a mapping or has no such key.
+func mappingEntry(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// mappingValue returns the value of key in the mapping node n, or nil if n
+// is not a mapping or has no such key.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	_, v := mappingEntry(n, key)
+	return v
+}
+
+// entryEmail returns the email of an owner, manager or member entry, either
+// a scalar or a mapping with an email and an expiry date.
+func entryEmail(n *yaml.Node) string {
+	if n.Kind == yaml.MappingNode {
+		if email := mappingValue(n, "email"); email != nil {
+			return email.Value
+		}
+		return ""
+	}
+	return n.Value
+}
+
+// entryEmailNode returns the key, if any, and the scalar node of the email
+// of an entry.
+func entryEmailNode(n *yaml.Node) (*yaml.Node, *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		return mappingEntry(n, "email")
+	}
+	return nil, n
+}
+
+// lineComment returns the comment at the end of the line of the value v of
+// the key k, wherever it was attached when parsing.
+func lineComment(k, v *yaml.Node) string {
+	if v.LineComment != "" {
+		return v.LineComment
+	}
+	if k != nil {
+		return k.LineComment
+	}
+	return ""
+}
+
+// renderScalar returns value as a YAML scalar in the given style.
+func renderScalar(value string, style yaml.Style) string {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		return strconv.Quote(value)
+	case style&yaml.SingleQuotedStyle != 0:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	default:
+		return value
+	}
+}
+
+// nodeEndLine returns the last line of n and its descendants, not counting
+// the lines of the content of literal and folded scalars; see
+// lineEditor.endLine.
+func nodeEndLine(n *yaml.Node) int {
+	end := n.Line
+	for _, c := range n.Content {
+		if l := nodeEndLine(c); l > end {
+			end = l
+		}
+	}
+	return end
+}
+
+func spaces(n int) string {
+	if n < 0 {
+		n = 0
+	}
+	return strings.Repeat(" ", n)
+}
+
+// leadingSpace returns the number of spaces and tabs line starts with.
+func leadingSpace(line []byte) int {
+	return len(line) - len(bytes.TrimLeft(line, " \t"))
+}
+
+// lineEditor removes, replaces and inserts whole lines of a file, addressed
+// by their number in the original content, starting at 1.
+type lineEditor struct {
+	lines   [][]byte
+	removed map[int]bool
+}
+
+func newLineEditor(content []byte) *lineEditor {
+	return &lineEditor{lines: bytes.SplitAfter(content, []byte("\n")), removed: map[int]bool{}}
+}
+
+// line returns the line number n.
+func (e *lineEditor) line(n int) []byte {
+	if n < 1 || n > len(e.lines) {
+		return nil
+	}
+	return e.lines[n-1]
+}
+
+// setLine replaces the line number n with content, which may be made of
+// several lines.
+func (e *lineEditor) setLine(n int, content []byte) {
+	e.lines[n-1] = content
+}
+
+// endLine returns the last line of n and its descendants, including the
+// content of their literal and folded scalars, which ends with the last line
+// more indented than the line the scalar starts on.
+func (e *lineEditor) endLine(n *yaml.Node) int {
+	end := n.Line
+	if n.Kind == yaml.ScalarNode && n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		indent := leadingSpace(e.line(n.Line))
+		for l := n.Line + 1; l <= len(e.lines); l++ {
+			line := e.line(l)
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			if leadingSpace(line) <= indent {
+				break
+			}
+			end = l
+		}
+	}
+	for _, c := range n.Content {
+		if l := e.endLine(c); l > end {
+			end = l
+		}
+	}
+	return end
+}
+
+// commentStart returns the first of the comment lines right above the line
+// number n that are indented like it, or n if there are none.
+func (e *lineEditor) commentStart(n int) int {
+	indent := leadingSpace(e.line(n))
+	for n > 1 {
+		above := e.line(n - 1)
+		if !bytes.HasPrefix(bytes.TrimLeft(above, " \t"), []byte("#")) || leadingSpace(above) != indent {
+			break
+		}
+		n--
+	}
+	return n
+}
+
+// removeLines removes the lines from first to last, along with the comment
+// lines right above first that are indented the same way.
+func (e *lineEditor) removeLines(first, last int) {
+	for n := e.commentStart(first); n <= last; n++ {
+		e.removed[n] = true
+	}
+}
+
+// insertAfter inserts text, made of whole lines, after the line number n.
+func (e *lineEditor) insertAfter(n int, text string) {
+	line := append([]byte(nil), e.line(n)...)
+	if len(line) > 0 && line[len(line)-1] != '\n' {
+		line = append(line, '\n')
+	}
+	e.setLine(n, append(line, text...))
+}
+
+// replaceScalar replaces the scalar n, which must be the last thing on its
+// line but for comment, with text.
+func (e *lineEditor) replaceScalar(n *yaml.Node, text, comment string) {
+	line := e.line(n.Line)
+	var b bytes.Buffer
+	b.Write(line[:n.Column-1])
+	b.WriteString(text)
+	if comment != "" {
+		b.WriteString(" " + comment)
+	}
+	b.WriteString("\n")
+	e.setLine(n.Line, b.Bytes())
+}
+
+// replaceFlowSequence rewrites list, a flow sequence on a single line, so
+// that it only contains the scalars in kept.
+func (e *lineEditor) replaceFlowSequence(list *yaml.Node, kept []*yaml.Node) error {
+	if nodeEndLine(list) != list.Line {
+		return fmt.Errorf("unable to rewrite the flow sequence at line %d as it spans several lines", list.Line)
+	}
+	line := e.line(list.Line)
+	start := list.Column - 1
+	if start < 0 || start >= len(line) || line[start] != '[' {
+		return fmt.Errorf("unable to find the flow sequence at line %d", list.Line)
+	}
+	end := bytes.IndexByte(line[start:], ']')
+	if end < 0 {
+		return fmt.Errorf("unable to find the end of the flow sequence at line %d", list.Line)
+	}
+	items := make([]string, 0, len(kept))
+	for _, n := range kept {
+		if n.Kind != yaml.ScalarNode {
+			return fmt.Errorf("unable to rewrite the flow sequence at line %d", list.Line)
+		}
+		items = append(items, renderScalar(n.Value, n.Style))
+	}
+	var b bytes.Buffer
+	b.Write(line[:start])
+	b.WriteString("[" + strings.Join(items, ", ") + "]")
+	b.Write(line[start+end+1:])
+	e.setLine(list.Line, b.Bytes())
+	return nil
+}
+
+// formatFlowSequence sorts list, a flow sequence of emails on a single line,
+// and normalizes its emails.
+func (e *lineEditor) formatFlowSequence(list *yaml.Node) error {
+	items := make([]*yaml.Node, 0, len(list.Content))
+	for _, n := range list.Content {
+		if n.Kind != yaml.ScalarNode {
+			return fmt.Errorf("unable to format the flow sequence at line %d", list.Line)
+		}
+		items = append(items, &yaml.Node{Kind: yaml.ScalarNode, Style: n.Style, Value: normalizeEmail(n.Value)})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Value < items[j].Value })
+	return e.replaceFlowSequence(list, items)
+}
+
+// formatBlockSequence sorts list, a block sequence of member entries, and
+// normalizes their emails. Each entry is moved along with the comment lines
+// right above it, while the blank lines between entries stay where they are.
+func (e *lineEditor) formatBlockSequence(list *yaml.Node) {
+	type entry struct {
+		email string
+		text  []byte
+	}
+	entries := make([]entry, 0, len(list.Content))
+	gaps := make([][]byte, len(list.Content))
+	starts := make([]int, len(list.Content))
+	for i, n := range list.Content {
+		starts[i] = e.commentStart(n.Line)
+	}
+	for i, n := range list.Content {
+		end := e.endLine(n)
+		if i+1 < len(list.Content) {
+			for end = starts[i+1] - 1; end > n.Line && len(bytes.TrimSpace(e.line(end))) == 0; end-- {
+				gaps[i] = append(append([]byte(nil), e.line(end)...), gaps[i]...)
+			}
+		}
+
+		email := entryEmail(n)
+		if k, v := entryEmailNode(n); v != nil && n.Style&yaml.FlowStyle == 0 && normalizeEmail(v.Value) != v.Value {
+			e.replaceScalar(v, renderScalar(normalizeEmail(v.Value), v.Style), lineComment(k, v))
+		}
+		var text []byte
+		for l := starts[i]; l <= end; l++ {
+			text = append(text, e.line(l)...)
+		}
+		if len(text) > 0 && text[len(text)-1] != '\n' {
+			text = append(text, '\n')
+		}
+		entries = append(entries, entry{email: normalizeEmail(email), text: text})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].email < entries[j].email })
+	var b []byte
+	for i, en := range entries {
+		b = append(b, en.text...)
+		b = append(b, gaps[i]...)
+	}
+	first, last := starts[0], e.endLine(list)
+	// Keep the file without a final newline if it had none.
+	if l := e.line(last); len(l) > 0 && l[len(l)-1] != '\n' {
+		b = b[:len(b)-1]
+	}
+	e.setLine(first, b)
+	for l := first + 1; l <= last; l++ {
+		e.removed[l] = true
+	}
+}
+
+// bytes returns the edited content.
+func (e *lineEditor) bytes() []byte {
+	var b bytes.Buffer
+	for i, l := range e.lines {
+		if !e.removed[i+1] {
+			b.Write(l)
+		}
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const editedGroupsFile = `# Groups of the foo SIG.
+groups:
+  # The team.
+  - email-id: team@example.com
+    name: team
+    description: |
+      The team of the foo SIG.
+    owners:
+      - alice@example.com # lead
+    members:
+      # On call until the end of the year.
+      - email: Carol@example.com
+        expires: 2026-12-31
+      - bob@example.com
+    settings:
+      WhoCanPostMessage: "ANYONE_CAN_POST" # announcements
+      ReconcileMembers: "true"
+
+  - email-id: leads@example.com
+    owners: [alice@example.com]
+`
+
+func TestGroupsFileEdits(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		edit     func(f *GroupsFile) error
+		expected string
+	}{
+		{
+			name: "add a member",
+			edit: func(f *GroupsFile) error { return f.AddMember("team@example.com", MemberRole, "dave@example.com") },
+			expected: `# Groups of the foo SIG.
+groups:
+  # The team.
+  - email-id: team@example.com
+    name: team
+    description: |
+      The team of the foo SIG.
+    owners:
+      - alice@example.com # lead
+    members:
+      # On call until the end of the year.
+      - email: Carol@example.com
+        expires: 2026-12-31
+      - bob@example.com
+      - dave@example.com
+    settings:
+      WhoCanPostMessage: "ANYONE_CAN_POST" # announcements
+      ReconcileMembers: "true"
+
+  - email-id: leads@example.com
+    owners: [alice@example.com]
+`,
+		},
+		{
+			name: "add the first manager and a flow owner",
+			edit: func(f *GroupsFile) error {
+				if err := f.AddMember("leads@example.com", ManagerRole, "bob@example.com"); err != nil {
+					return err
+				}
+				return f.AddMember("leads@example.com", OwnerRole, "carol@example.com")
+			},
+			expected: `# Groups of the foo SIG.
+groups:
+  # The team.
+  - email-id: team@example.com
+    name: team
+    description: |
+      The team of the foo SIG.
+    owners:
+      - alice@example.com # lead
+    members:
+      # On call until the end of the year.
+      - email: Carol@example.com
+        expires: 2026-12-31
+      - bob@example.com
+    settings:
+      WhoCanPostMessage: "ANYONE_CAN_POST" # announcements
+      ReconcileMembers: "true"
+
+  - email-id: leads@example.com
+    owners: [alice@example.com, carol@example.com]
+    managers:
+      - bob@example.com
+`,
+		},
+		{
+			name: "move a member keeping its expiry",
+			edit: func(f *GroupsFile) error { return f.MoveMember("team@example.com", "carol@example.com", OwnerRole) },
+			expected: `# Groups of the foo SIG.
+groups:
+  # The team.
+  - email-id: team@example.com
+    name: team
+    description: |
+      The team of the foo SIG.
+    owners:
+      - alice@example.com # lead
+      - email: Carol@example.com
+        expires: 2026-12-31
+    members:
+      - bob@example.com
+    settings:
+      WhoCanPostMessage: "ANYONE_CAN_POST" # announcements
+      ReconcileMembers: "true"
+
+  - email-id: leads@example.com
+    owners: [alice@example.com]
+`,
+		},
+		{
+			name: "set settings",
+			edit: func(f *GroupsFile) error {
+				if err := f.SetSetting("team@example.com", "WhoCanPostMessage", "ALL_MEMBERS_CAN_POST"); err != nil {
+					return err
+				}
+				if err := f.SetSetting("team@example.com", "AllowWebPosting", "false"); err != nil {
+					return err
+				}
+				return f.SetSetting("leads@example.com", "WhoCanJoin", "INVITED_CAN_JOIN")
+			},
+			expected: `# Groups of the foo SIG.
+groups:
+  # The team.
+  - email-id: team@example.com
+    name: team
+    description: |
+      The team of the foo SIG.
+    owners:
+      - alice@example.com # lead
+    members:
+      # On call until the end of the year.
+      - email: Carol@example.com
+        expires: 2026-12-31
+      - bob@example.com
+    settings:
+      WhoCanPostMessage: "ALL_MEMBERS_CAN_POST" # announcements
+      ReconcileMembers: "true"
+      AllowWebPosting: "false"
+
+  - email-id: leads@example.com
+    owners: [alice@example.com]
+    settings:
+      WhoCanJoin: "INVITED_CAN_JOIN"
+`,
+		},
+		{
+			name: "remove settings",
+			edit: func(f *GroupsFile) error {
+				if err := f.RemoveSetting("team@example.com", "ReconcileMembers"); err != nil {
+					return err
+				}
+				return f.RemoveSetting("team@example.com", "WhoCanPostMessage")
+			},
+			expected: `# Groups of the foo SIG.
+groups:
+  # The team.
+  - email-id: team@example.com
+    name: team
+    description: |
+      The team of the foo SIG.
+    owners:
+      - alice@example.com # lead
+    members:
+      # On call until the end of the year.
+      - email: Carol@example.com
+        expires: 2026-12-31
+      - bob@example.com
+
+  - email-id: leads@example.com
+    owners: [alice@example.com]
+`,
+		},
+		{
+			name: "format",
+			edit: func(f *GroupsFile) error { return f.Format() },
+			expected: `# Groups of the foo SIG.
+groups:
+  # The team.
+  - email-id: team@example.com
+    name: team
+    description: |
+      The team of the foo SIG.
+    owners:
+      - alice@example.com # lead
+    members:
+      - bob@example.com
+      # On call until the end of the year.
+      - email: carol@example.com
+        expires: 2026-12-31
+    settings:
+      WhoCanPostMessage: "ANYONE_CAN_POST" # announcements
+      ReconcileMembers: "true"
+
+  - email-id: leads@example.com
+    owners: [alice@example.com]
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseGroupsFile([]byte(editedGroupsFile))
+			if err != nil {
+				t.Fatalf("unexpected error parsing: %v", err)
+			}
+			if err := tc.edit(f); err != nil {
+				t.Fatalf("unexpected error editing: %v", err)
+			}
+			if got := string(f.Bytes()); got != tc.expected {
+				t.Errorf("unexpected content:\nexpected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestGroupsFileEditErrors(t *testing.T) {
+	f, err := ParseGroupsFile([]byte(editedGroupsFile))
+	if err != nil {
+		t.Fatalf("unexpected error parsing: %v", err)
+	}
+	if err := f.AddMember("team@example.com", MemberRole, "Alice@example.com"); err == nil {
+		t.Error("expected an error adding an owner as a member")
+	}
+	if err := f.AddMember("other@example.com", MemberRole, "bob@example.com"); err == nil {
+		t.Error("expected an error adding a member to a group not in the file")
+	}
+	if err := f.AddMember("team@example.com", MemberRole, "bob@example.com"); err != nil {
+		t.Errorf("unexpected error adding an existing member: %v", err)
+	}
+	if err := f.SetSetting("team@example.com", "description", "x"); err != nil {
+		t.Errorf("unexpected error setting a setting: %v", err)
+	}
+	if string(f.Bytes()) == editedGroupsFile {
+		t.Error("expected the setting to be added")
+	}
+	if ids := f.GroupIDs(); !reflect.DeepEqual(ids, []string{"team@example.com", "leads@example.com"}) {
+		t.Errorf("unexpected group ids %q", ids)
+	}
+}
+
+func TestFormatGroupsFiles(t *testing.T) {
+	rootDir := t.TempDir()
+	files := map[string]string{
+		"groups.yaml":         editedGroupsFile,
+		"sig-bar/groups.yaml": "groups:\n  - email-id: bar@example.com\n    owners: [b@example.com, a@example.com]\n",
+		"sig-baz/groups.yaml": "groups:\n  - email-id: baz@example.com\n    owners:\n      - a@example.com\n",
+		"sig-qux/groups.json": `{"groups": [{"email-id": "qux@example.com", "members": ["B@example.com", "a@example.com"]}]}`,
+	}
+	writeTree(t, rootDir, files)
+
+	opts := GroupsLoadOptions{Patterns: []string{"groups.yaml", "groups.json"}}
+	expected := []string{"groups.yaml", "sig-bar/groups.yaml"}
+	unformatted, err := FormatGroupsFiles(rootDir, opts, false)
+	if err != nil {
+		t.Fatalf("unexpected error checking: %v", err)
+	}
+	if !reflect.DeepEqual(expected, unformatted) {
+		t.Errorf("expected unformatted files %q, got %q", expected, unformatted)
+	}
+	if got, _ := ioutil.ReadFile(filepath.Join(rootDir, "groups.yaml")); string(got) != editedGroupsFile {
+		t.Error("expected checking not to rewrite the files")
+	}
+
+	if _, err := FormatGroupsFiles(rootDir, opts, true); err != nil {
+		t.Fatalf("unexpected error formatting: %v", err)
+	}
+	if got, _ := ioutil.ReadFile(filepath.Join(rootDir, "sig-bar/groups.yaml")); string(got) != "groups:\n  - email-id: bar@example.com\n    owners: [a@example.com, b@example.com]\n" {
+		t.Errorf("unexpected formatted content:\n%s", got)
+	}
+	if unformatted, err := FormatGroupsFiles(rootDir, opts, false); err != nil || len(unformatted) != 0 {
+		t.Errorf("expected the files to be formatted, got %q, %v", unformatted, err)
+	}
+}